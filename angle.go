@@ -0,0 +1,86 @@
+package sec
+
+import (
+	"math"
+)
+
+// AngleMode is a unit of angles.
+type AngleMode int
+
+const (
+	Radians AngleMode = iota
+	Degrees
+	Gradians
+)
+
+// angleUnits maps the suffixes of angle literals to their units.
+var angleUnits = map[string]AngleMode{
+	"rad":  Radians,
+	"deg":  Degrees,
+	"grad": Gradians,
+}
+
+func (m AngleMode) String() (str string) {
+	switch m {
+	case Radians:
+		str = "rad"
+	case Degrees:
+		str = "deg"
+	case Gradians:
+		str = "grad"
+	default:
+		str = "unknown"
+	}
+	return
+}
+
+// toRadians converts x from m to radians. Unknown modes are taken as
+// radians.
+func (m AngleMode) toRadians(x float64) float64 {
+	switch m {
+	case Degrees:
+		return x * math.Pi / 180
+	case Gradians:
+		return x * math.Pi / 200
+	}
+	return x
+}
+
+// fromRadians converts x from radians to m.
+func (m AngleMode) fromRadians(x float64) float64 {
+	switch m {
+	case Degrees:
+		return x * 180 / math.Pi
+	case Gradians:
+		return x * 200 / math.Pi
+	}
+	return x
+}
+
+// convert converts x from m to mode to.
+func (m AngleMode) convert(x float64, to AngleMode) float64 {
+	if m == to {
+		return x
+	}
+	return to.fromRadians(m.toRadians(x))
+}
+
+// trig makes a builtin of a trigonometric function taking an angle in the
+// Env's AngleMode.
+func trig(f func(float64) float64) builtin {
+	return builtin{1, 1, func(env Env, args []float64) (float64, error) {
+		return f(env.AngleMode.toRadians(args[0])), nil
+	}}
+}
+
+// arc makes a builtin of an inverse trigonometric function returning an
+// angle in the Env's AngleMode.
+func arc(f func(float64) float64) builtin {
+	return builtin{1, 1, func(env Env, args []float64) (float64, error) {
+		return env.AngleMode.fromRadians(f(args[0])), nil
+	}}
+}
+
+func atan2(env Env, args []float64) (float64, error) {
+	return env.AngleMode.fromRadians(math.Atan2(args[0], args[1])), nil
+}
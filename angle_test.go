@@ -0,0 +1,52 @@
+package sec
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestAngleMode(t *testing.T) {
+	cases := []struct {
+		mode AngleMode
+		src  string
+		want float64
+	}{
+		{Radians, "sin(pi / 2)", 1},
+		{Degrees, "sin(90)", 1},
+		{Gradians, "sin(100)", 1},
+		{Degrees, "acos(0)", 90},
+		{Gradians, "atan2(1, 0)", 100},
+		{Radians, "180deg", math.Pi},
+		{Degrees, "180deg", 180},
+		{Degrees, "0.5rad * 2", 180 / math.Pi},
+		{Gradians, "90deg", 100},
+		{Degrees, "cos(60deg) + tan(50grad)", 1.5},
+		{Radians, "sin(30deg)", 0.5},
+	}
+
+	env := Env{}
+	env.LoadMath()
+	for _, c := range cases {
+		expr, err := Parse(c.src)
+		if err != nil {
+			t.Fatal(c.src, err)
+		}
+		env.AngleMode = c.mode
+		if got, err := expr.Val(env); err != nil {
+			t.Fatal(c.src, err)
+		} else if math.Abs(got-c.want) > 1e-12 {
+			t.Fatalf("%s in %s: expect %v, got %v", c.src, c.mode, c.want, got)
+		}
+	}
+}
+
+func TestAngleSuffixNeedsNoBlank(t *testing.T) {
+	var uerr ErrUnexpected
+	if _, err := Parse("30 deg"); !errors.As(err, &uerr) {
+		t.Fatal("expect ErrUnexpected error, got", err)
+	}
+	if _, err := Parse("30km"); !errors.As(err, &uerr) {
+		t.Fatal("expect ErrUnexpected error, got", err)
+	}
+}
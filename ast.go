@@ -0,0 +1,231 @@
+package sec
+
+import (
+	"context"
+	"strconv"
+)
+
+// Expr is a parsed expression, a tree of the node types below.
+type Expr interface {
+	Val(env Env) (val float64, err error)
+	// ValContext is like Val, but stops evaluating with an ErrInterrupted
+	// once ctx is done. ctx is also passed to functions which take a
+	// context.Context as their first parameter.
+	ValContext(ctx context.Context, env Env) (val float64, err error)
+	// Check reports the problems Val would run into in env without
+	// evaluating: undeclared variables and functions, calls with a wrong
+	// number of arguments and illegal functions. It returns an ErrorList
+	// of all of them in source order, or nil.
+	Check(env Env) error
+	// Vars returns the names of the variables referenced, with the
+	// positions of their occurrences in source order. Constants inlined by
+	// the parser are not included.
+	Vars() map[string][]Position
+	// Funcs returns the names of the functions called, with the positions
+	// of the calls in source order.
+	Funcs() map[string][]Position
+	// String returns the expression printed by a zero Printer.
+	String() string
+	// Pos returns the position of the node in the source, that of the
+	// operator for Unary and Binary.
+	Pos() Position
+
+	check(env Env, errs *ErrorList)
+}
+
+type (
+	// Unary is an operator applied to one operand, like -x.
+	Unary struct {
+		op   token
+		expr Expr
+	}
+
+	// Binary is an operator applied to two operands, like x + y.
+	Binary struct {
+		op   token
+		l, r Expr
+	}
+
+	// Variable is a reference to a variable.
+	Variable token
+
+	// Constant is a reference to a constant inlined by the parser, see
+	// Parser.Consts.
+	Constant struct {
+		token
+		val float64
+	}
+
+	// Literal is a number literal, like 42, 0x2a or 3.14.
+	Literal struct {
+		token
+		val float64 // parsed when the literal is
+	}
+
+	// Angle is a number literal with an angle unit suffix, like 30deg.
+	Angle struct {
+		lit  Literal
+		unit AngleMode
+	}
+
+	// Call is a function call, like max(x, 1).
+	Call struct {
+		token
+		args []Expr
+	}
+)
+
+// Op is an operator.
+type Op int
+
+const (
+	OpAdd      Op = iota + 1 // +
+	OpSub                    // -
+	OpMul                    // *
+	OpDiv                    // /
+	OpMod                    // %
+	OpPow                    // **
+	OpFloorDiv               // //
+)
+
+// ops maps the token types of operators to operators.
+var ops = map[tokenType]Op{
+	plus:        OpAdd,
+	minus:       OpSub,
+	star:        OpMul,
+	slash:       OpDiv,
+	percent:     OpMod,
+	doubleStar:  OpPow,
+	doubleSlash: OpFloorDiv,
+}
+
+func (op Op) String() (str string) {
+	switch op {
+	case OpAdd:
+		str = "+"
+	case OpSub:
+		str = "-"
+	case OpMul:
+		str = "*"
+	case OpDiv:
+		str = "/"
+	case OpMod:
+		str = "%"
+	case OpPow:
+		str = "**"
+	case OpFloorDiv:
+		str = "//"
+	default:
+		str = "unknown"
+	}
+	return
+}
+
+func (v Variable) Pos() Position { return v.Position }
+func (c Constant) Pos() Position { return c.Position }
+func (l Literal) Pos() Position  { return l.Position }
+func (a Angle) Pos() Position    { return a.lit.Position }
+func (u Unary) Pos() Position    { return u.op.Position }
+func (b Binary) Pos() Position   { return b.op.Position }
+func (c Call) Pos() Position     { return c.token.Position }
+
+// Op returns OpAdd or OpSub.
+func (u Unary) Op() Op { return ops[u.op.typ] }
+
+// Operand returns the operand of u.
+func (u Unary) Operand() Expr { return u.expr }
+
+// Op returns the operator of b.
+func (b Binary) Op() Op { return ops[b.op.typ] }
+
+// Left returns the left operand of b.
+func (b Binary) Left() Expr { return b.l }
+
+// Right returns the right operand of b.
+func (b Binary) Right() Expr { return b.r }
+
+// Name returns the name of the variable.
+func (v Variable) Name() string { return v.txt }
+
+// Name returns the name of the constant.
+func (c Constant) Name() string { return c.txt }
+
+// Value returns the value of the constant.
+func (c Constant) Value() float64 { return c.val }
+
+// Text returns the literal as written in the source.
+func (l Literal) Text() string { return l.txt }
+
+// Value returns the value of the literal.
+func (l Literal) Value() float64 { return l.val }
+
+// newLiteral makes a Literal of the number literal tk.
+func newLiteral(tk token) Literal {
+	var val float64
+	switch tk.typ {
+	case integer, float:
+		val, _ = strconv.ParseFloat(tk.txt, 64)
+	case binLiteral, octLiteral, hexLiteral:
+		t, _ := strconv.ParseInt(tk.txt, 0, 64)
+		val = float64(t)
+	}
+	return Literal{tk, val}
+}
+
+// Literal returns the number literal of a.
+func (a Angle) Literal() Literal { return a.lit }
+
+// Unit returns the unit suffix of a.
+func (a Angle) Unit() AngleMode { return a.unit }
+
+// Name returns the name of the function called.
+func (c Call) Name() string { return c.txt }
+
+// Args returns the arguments of c. It must not be modified.
+func (c Call) Args() []Expr { return c.args }
+
+// Visitor's Visit method is called for each node encountered by Walk. If the
+// result w is not nil, Walk visits each child of the node with w, followed
+// by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Expr) (w Visitor)
+}
+
+// Walk traverses an expression in depth-first order, like ast.Walk of the
+// go/ast package: it starts by calling v.Visit(node), and then walks the
+// children of node with the visitor returned, if it is not nil.
+func Walk(v Visitor, node Expr) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+	switch n := node.(type) {
+	case Unary:
+		Walk(v, n.expr)
+	case Binary:
+		Walk(v, n.l)
+		Walk(v, n.r)
+	case Angle:
+		Walk(v, n.lit)
+	case Call:
+		for _, arg := range n.args {
+			Walk(v, arg)
+		}
+	}
+	v.Visit(nil)
+}
+
+type inspector func(Expr) bool
+
+func (f inspector) Visit(node Expr) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses an expression in depth-first order: it starts by calling
+// f(node), and if f returns true, Inspect invokes f for each child of node
+// recursively, followed by a call of f(nil).
+func Inspect(node Expr, f func(Expr) bool) {
+	Walk(inspector(f), node)
+}
@@ -0,0 +1,84 @@
+package sec
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestASTAccessors(t *testing.T) {
+	psr := Parser{Consts: Vars{"k": 2}}
+	expr, err := psr.Parse("-x + f(0x10, 30deg) ** k")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	add, ok := expr.(Binary)
+	if !ok || add.Op() != OpAdd || add.Pos() != (Position{1, 4}) {
+		t.Fatalf("unexpected %#v", expr)
+	}
+	neg := add.Left().(Unary)
+	if neg.Op() != OpSub || neg.Operand().(Variable).Name() != "x" {
+		t.Fatalf("unexpected %#v", neg)
+	}
+	pow := add.Right().(Binary)
+	if pow.Op() != OpPow || pow.Op().String() != "**" {
+		t.Fatalf("unexpected %#v", pow)
+	}
+	if k := pow.Right().(Constant); k.Name() != "k" || k.Value() != 2 {
+		t.Fatalf("unexpected %#v", k)
+	}
+
+	call := pow.Left().(Call)
+	if call.Name() != "f" || len(call.Args()) != 2 || call.Pos() != (Position{1, 6}) {
+		t.Fatalf("unexpected %#v", call)
+	}
+	if lit := call.Args()[0].(Literal); lit.Text() != "0x10" || lit.Value() != 16 {
+		t.Fatalf("unexpected %#v", lit)
+	}
+	if a := call.Args()[1].(Angle); a.Unit() != Degrees || a.Literal().Value() != 30 {
+		t.Fatalf("unexpected %#v", a)
+	}
+}
+
+type nodeCounter map[string]int
+
+func (c nodeCounter) Visit(node Expr) Visitor {
+	if node != nil {
+		c[reflect.TypeOf(node).Name()]++
+	}
+	return c
+}
+
+func TestWalk(t *testing.T) {
+	expr, _ := Parse("max(a, -b, 2) * (a + 1.5rad)")
+	counts := nodeCounter{}
+	Walk(counts, expr)
+	expected := nodeCounter{"Binary": 2, "Call": 1, "Variable": 3, "Unary": 1, "Literal": 2, "Angle": 1}
+	if !reflect.DeepEqual(counts, expected) {
+		t.Fatal("expect", expected, "got", counts)
+	}
+
+	// Inspect stops descending when f returns false, and calls f(nil) after
+	// the children of a node
+	var trace []string
+	Inspect(expr, func(node Expr) bool {
+		switch n := node.(type) {
+		case nil:
+			trace = append(trace, ")")
+		case Call:
+			trace = append(trace, n.Name())
+			return false
+		case Binary:
+			trace = append(trace, n.Op().String()+"(")
+		case Variable:
+			trace = append(trace, n.Name()+"(")
+		default:
+			trace = append(trace, "?(")
+		}
+		return true
+	})
+	if s := strings.Join(trace, ""); s != "*(max+(a()?(?())))" {
+		t.Fatal("unexpected trace", s)
+	}
+}
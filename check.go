@@ -0,0 +1,68 @@
+package sec
+
+func check(e Expr, env Env) error {
+	var errs ErrorList
+	e.check(env, &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func (v Variable) Check(env Env) error { return check(v, env) }
+func (c Constant) Check(env Env) error { return check(c, env) }
+func (l Literal) Check(env Env) error  { return check(l, env) }
+func (a Angle) Check(env Env) error    { return check(a, env) }
+func (u Unary) Check(env Env) error    { return check(u, env) }
+func (b Binary) Check(env Env) error   { return check(b, env) }
+func (c Call) Check(env Env) error     { return check(c, env) }
+
+func (v Variable) check(env Env, errs *ErrorList) {
+	if _, ok := env.Lookup(v.txt); !ok {
+		*errs = append(*errs, ErrUndeclaredVar{v.Position, v.txt})
+	}
+}
+
+func (Constant) check(Env, *ErrorList) {}
+func (Literal) check(Env, *ErrorList)  {}
+func (Angle) check(Env, *ErrorList)    {}
+
+func (u Unary) check(env Env, errs *ErrorList) { u.expr.check(env, errs) }
+
+func (b Binary) check(env Env, errs *ErrorList) {
+	b.l.check(env, errs)
+	b.r.check(env, errs)
+}
+
+func (c Call) check(env Env, errs *ErrorList) {
+	if err := c.checkFunc(env); err != nil {
+		*errs = append(*errs, err)
+	}
+	for _, arg := range c.args {
+		arg.check(env, errs)
+	}
+}
+
+// checkFunc checks the function called by c.
+func (c Call) checkFunc(env Env) error {
+	fun, ok := env.LookupFunc(c.txt)
+	if !ok {
+		return ErrUndeclaredFunc{c.token.Position, c.txt}
+	}
+	f, err := toFunction(c.txt, fun)
+	if err != nil {
+		return ErrIllegalFunc{c.token.Position, err}
+	}
+	if o, ok := f.(overloaded); ok {
+		if _, ok = o.pick(len(c.args)); !ok {
+			return ErrNoOverload{c.token.Position, c.txt, len(c.args), o.signatures(c.txt)}
+		}
+		return nil
+	}
+	if min, max := f.arity(); len(c.args) < min {
+		return ErrTooFewArgsToCall{c.token.Position, c.txt}
+	} else if len(c.args) > max && max >= 0 {
+		return ErrTooManyArgsToCall{c.token.Position, c.txt}
+	}
+	return nil
+}
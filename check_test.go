@@ -0,0 +1,55 @@
+package sec
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	env := Env{
+		Vars: Vars{"x": 1},
+		Funcs: Funcs{
+			"f":    Func2(func(x, y float64) float64 { return x + y }),
+			"bad":  func(s string) float64 { return 0 },
+			"over": Overloads{Func0(func() float64 { return 0 }), Func2(func(x, y float64) float64 { return 0 })},
+		},
+	}
+	env.LoadMath()
+
+	expr, err := Parse("f(x, y) + g(1) -\n f(1) * bad(z) + over(1) + f(1, 2, 3) + pi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = expr.Check(env)
+	var list ErrorList
+	if !errors.As(err, &list) {
+		t.Fatal("expect ErrorList error, got", err)
+	}
+
+	expected := ErrorList{
+		ErrUndeclaredVar{Position{1, 6}, "y"},
+		ErrUndeclaredFunc{Position{1, 11}, "g"},
+		ErrTooFewArgsToCall{Position{2, 2}, "f"},
+		ErrIllegalFunc{Position{2, 9}, ErrParamNotFloat64{"bad", 1}},
+		ErrUndeclaredVar{Position{2, 13}, "z"},
+		ErrNoOverload{Position{2, 18}, "over", 1, []string{"over()", "over(x1, x2)"}},
+		ErrTooManyArgsToCall{Position{2, 28}, "f"},
+	}
+	if !reflect.DeepEqual(list, expected) {
+		t.Fatalf("expect %#v, got %#v", expected, list)
+	}
+	if err.Error() != `undeclared variable "y" (and 6 more errors)` {
+		t.Fatal("unexpected message", err)
+	}
+
+	var ierr ErrParamNotFloat64
+	if !errors.As(list[3], &ierr) {
+		t.Fatal("expect ErrIllegalFunc to wrap the error of Funcs.Check")
+	}
+
+	expr, _ = Parse("f(x, over()) + sin(30deg)")
+	if err := expr.Check(env); err != nil {
+		t.Fatal("expect no error, got", err)
+	}
+}
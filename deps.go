@@ -0,0 +1,37 @@
+package sec
+
+// references returns the names of the variables or the functions referenced
+// in e, with the positions of their occurrences.
+func references(e Expr, funcs bool) map[string][]Position {
+	refs := make(map[string][]Position)
+	Inspect(e, func(node Expr) bool {
+		switch n := node.(type) {
+		case Variable:
+			if !funcs {
+				refs[n.txt] = append(refs[n.txt], n.Position)
+			}
+		case Call:
+			if funcs {
+				refs[n.txt] = append(refs[n.txt], n.token.Position)
+			}
+		}
+		return true
+	})
+	return refs
+}
+
+func (v Variable) Vars() map[string][]Position { return references(v, false) }
+func (c Constant) Vars() map[string][]Position { return references(c, false) }
+func (l Literal) Vars() map[string][]Position  { return references(l, false) }
+func (a Angle) Vars() map[string][]Position    { return references(a, false) }
+func (u Unary) Vars() map[string][]Position    { return references(u, false) }
+func (b Binary) Vars() map[string][]Position   { return references(b, false) }
+func (c Call) Vars() map[string][]Position     { return references(c, false) }
+
+func (v Variable) Funcs() map[string][]Position { return references(v, true) }
+func (c Constant) Funcs() map[string][]Position { return references(c, true) }
+func (l Literal) Funcs() map[string][]Position  { return references(l, true) }
+func (a Angle) Funcs() map[string][]Position    { return references(a, true) }
+func (u Unary) Funcs() map[string][]Position    { return references(u, true) }
+func (b Binary) Funcs() map[string][]Position   { return references(b, true) }
+func (c Call) Funcs() map[string][]Position     { return references(c, true) }
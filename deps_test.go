@@ -0,0 +1,36 @@
+package sec
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReferences(t *testing.T) {
+	psr := Parser{Consts: Vars{"pi": 3.14}}
+	expr, err := psr.Parse("max(price * qty, min(price, 10)) +\n  pi * r ** 2 + max(r)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vars := map[string][]Position{
+		"price": {{1, 5}, {1, 22}},
+		"qty":   {{1, 13}},
+		"r":     {{2, 8}, {2, 21}},
+	}
+	if got := expr.Vars(); !reflect.DeepEqual(got, vars) {
+		t.Fatal("expect", vars, "got", got)
+	}
+
+	funcs := map[string][]Position{
+		"max": {{1, 1}, {2, 17}},
+		"min": {{1, 18}},
+	}
+	if got := expr.Funcs(); !reflect.DeepEqual(got, funcs) {
+		t.Fatal("expect", funcs, "got", got)
+	}
+
+	expr, _ = Parse("42")
+	if len(expr.Vars()) != 0 || len(expr.Funcs()) != 0 {
+		t.Fatal("expect no references")
+	}
+}
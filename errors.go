@@ -2,6 +2,7 @@ package sec
 
 import (
 	"fmt"
+	"strings"
 )
 
 type (
@@ -19,7 +20,7 @@ type (
 		Position
 	}
 
-	// function's Nth parameter is not float64
+	// function's Nth parameter is not of a numeric or bool type
 	ErrParamNotFloat64 struct {
 		Name string // functhon name
 		N    int    // Nth parameter
@@ -30,7 +31,7 @@ type (
 		Name string
 	}
 
-	// function return too many values
+	// function return too many values, or its second value is not an error
 	ErrFuncReturnTooManyVal struct {
 		Name string // function name
 	}
@@ -40,6 +41,7 @@ type (
 		Name string // function name
 	}
 
+	// function's return value is not of a numeric or bool type
 	ErrReturnValNotFloat64 struct {
 		Name string
 	}
@@ -74,6 +76,111 @@ type (
 		Position
 		Name string
 	}
+
+	// function called at Position is illegal, Err is one of the errors
+	// returned by Funcs.Check
+	ErrIllegalFunc struct {
+		Position
+		Err error
+	}
+
+	// ErrorList is a list of errors, like the problems found by Expr.Check
+	ErrorList []error
+
+	// none of the overloads of the function called at Position takes N
+	// arguments
+	ErrNoOverload struct {
+		Position
+		Name       string   // function name
+		N          int      // number of arguments
+		Signatures []string // signatures of the overloads
+	}
+
+	// function called at Position returned an error
+	ErrFuncFailed struct {
+		Position
+		Name string // function name
+		Err  error  // the error returned by the function
+	}
+
+	// Nth argument of the function called at Position can not be converted
+	// to the type of its parameter
+	ErrArgConversion struct {
+		Position
+		Name string  // function name
+		N    int     // Nth argument
+		Val  float64 // the argument
+		Type string  // the type of the parameter
+	}
+
+	// function called at Position panicked
+	ErrFuncPanicked struct {
+		Position
+		Name  string      // function name
+		Value interface{} // the value passed to panic
+		Stack []byte      // stack trace of the panicking goroutine
+	}
+
+	// evaluation stopped at Position because its context is done
+	ErrInterrupted struct {
+		Position
+		Err error // the error of the context
+	}
+
+	// evaluation visited more than EvalOptions.MaxSteps nodes
+	ErrStepLimit struct {
+		Position
+	}
+
+	// function calls nest deeper than EvalOptions.MaxCallDepth
+	ErrCallDepthLimit struct {
+		Position
+		Name string // function name
+	}
+
+	// an operation on finite values overflowed to infinity
+	ErrOverflow struct {
+		Position
+	}
+
+	// expression nests deeper than Parser.MaxDepth
+	ErrParseDepthLimit struct {
+		Position
+	}
+
+	// source has more than Parser.MaxTokens tokens
+	ErrTokenLimit struct {
+		Position
+	}
+
+	// an iterative function failed to converge to a solution
+	ErrNoConvergence struct{}
+
+	// function's exact result can not be represented by a float64
+	ErrPrecisionLoss struct{}
+
+	// function's Nth argument is not an integer
+	ErrArgNotInteger struct {
+		N int // Nth argument
+	}
+
+	// value of Key in a source read by the Read methods of Vars is invalid,
+	// Line is 0 for sources without lines
+	ErrLoad struct {
+		Line int
+		Key  string
+		Err  error
+	}
+
+	// value passed to EnvFromStruct is not a struct or a pointer to a struct
+	ErrNotStruct struct {
+		Type string
+	}
+
+	// function's Nth argument is outside of the function's domain
+	ErrArgOutOfRange struct {
+		N int // Nth argument
+	}
 )
 
 func (t secError) Unwrap() error { return t.err }
@@ -90,9 +197,8 @@ func (e ErrUnexpectedEOF) Error() string {
 	return "unexpected EOF"
 }
 
-func (f ErrParamNotFloat64) Error() string {
-	var text string
-	switch f.N {
+func ordinal(n int) (text string) {
+	switch n {
 	case 1:
 		text = "first"
 	case 2:
@@ -100,10 +206,13 @@ func (f ErrParamNotFloat64) Error() string {
 	case 3:
 		text = "third"
 	default:
-		text = fmt.Sprintf("%dth", f.N)
+		text = fmt.Sprintf("%dth", n)
 	}
+	return
+}
 
-	return fmt.Sprintf("the %s parameter of function %q is not float64", text, f.Name)
+func (f ErrParamNotFloat64) Error() string {
+	return fmt.Sprintf("the %s parameter of function %q is not numeric", ordinal(f.N), f.Name)
 }
 
 func (e ErrNotFunction) Error() string {
@@ -111,7 +220,7 @@ func (e ErrNotFunction) Error() string {
 }
 
 func (e ErrFuncReturnTooManyVal) Error() string {
-	return fmt.Sprintf("function %q must return only one value", e.Name)
+	return fmt.Sprintf("function %q must return only one value and an optional error", e.Name)
 }
 
 func (e ErrFuncNoReturnVal) Error() string {
@@ -119,7 +228,7 @@ func (e ErrFuncNoReturnVal) Error() string {
 }
 
 func (e ErrReturnValNotFloat64) Error() string {
-	return fmt.Sprintf("function %q must return a float64 value", e.Name)
+	return fmt.Sprintf("function %q must return a numeric value", e.Name)
 }
 
 func baseToStr(bit int) (str string) {
@@ -159,3 +268,103 @@ func (e ErrTooFewArgsToCall) Error() string {
 func (e ErrTooManyArgsToCall) Error() string {
 	return fmt.Sprintf("too many arguments to call %q", e.Name)
 }
+
+func (e ErrIllegalFunc) Unwrap() error { return e.Err }
+
+func (e ErrIllegalFunc) Error() string { return e.Err.Error() }
+
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", l[0], len(l)-1)
+}
+
+func (e ErrNoOverload) Error() string {
+	return fmt.Sprintf("no overload of function %q takes %d arguments, candidates are %s",
+		e.Name, e.N, strings.Join(e.Signatures, ", "))
+}
+
+func (e ErrFuncFailed) Unwrap() error { return e.Err }
+
+func (e ErrFuncFailed) Error() string {
+	return fmt.Sprintf("function %q failed: %v", e.Name, e.Err)
+}
+
+func (e ErrArgConversion) Error() string {
+	return fmt.Sprintf("can not convert %v to %s for the %s argument of function %q",
+		e.Val, e.Type, ordinal(e.N), e.Name)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e ErrFuncPanicked) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+func (e ErrFuncPanicked) Error() string {
+	return fmt.Sprintf("function %q panicked: %v", e.Name, e.Value)
+}
+
+func (e ErrInterrupted) Unwrap() error { return e.Err }
+
+func (e ErrInterrupted) Error() string {
+	return fmt.Sprintf("evaluation interrupted at %s: %v", e.Position, e.Err)
+}
+
+func (e ErrStepLimit) Error() string {
+	return "evaluation step limit exceeded"
+}
+
+func (e ErrCallDepthLimit) Error() string {
+	return fmt.Sprintf("call depth limit exceeded calling %q", e.Name)
+}
+
+func (e ErrOverflow) Error() string {
+	return "numeric overflow"
+}
+
+func (e ErrParseDepthLimit) Error() string {
+	return "expression nested too deeply"
+}
+
+func (e ErrTokenLimit) Error() string {
+	return "too many tokens"
+}
+
+func (e ErrNoConvergence) Error() string {
+	return "did not converge"
+}
+
+func (e ErrPrecisionLoss) Error() string {
+	return "result does not fit in a float64"
+}
+
+func (e ErrArgNotInteger) Error() string {
+	return fmt.Sprintf("the %s argument is not an integer", ordinal(e.N))
+}
+
+func (e ErrArgOutOfRange) Error() string {
+	return fmt.Sprintf("the %s argument is out of range", ordinal(e.N))
+}
+
+func (e ErrNotStruct) Error() string {
+	return fmt.Sprintf("%s is not a struct or a pointer to a struct", e.Type)
+}
+
+func (e ErrLoad) Unwrap() error { return e.Err }
+
+func (e ErrLoad) Error() string {
+	var b strings.Builder
+	if e.Line > 0 {
+		fmt.Fprintf(&b, "line %d: ", e.Line)
+	}
+	if e.Key != "" {
+		fmt.Fprintf(&b, "%q: ", e.Key)
+	}
+	b.WriteString(e.Err.Error())
+	return b.String()
+}
@@ -1,57 +1,167 @@
 package sec
 
 import (
+	"context"
 	"math"
-	"reflect"
-	"strconv"
+	"runtime/debug"
 )
 
-type Expr interface {
-	Val(env Env) (val float64, err error)
+// evaluator holds the state of an evaluation. It lives on the stack of Val:
+// ctx is held through a pointer, since a context leaks to the heap when its
+// methods are called, and everything loaded from the evaluator itself would
+// leak along with it.
+type evaluator struct {
+	ctx       *context.Context
+	done      <-chan struct{}
+	env       *Env
+	steps     int // number of nodes visited
+	callDepth int // number of calls being evaluated
 }
 
-type (
-	unary struct {
-		op   token
-		expr Expr
+func newEvaluator(ctx *context.Context, env *Env) evaluator {
+	return evaluator{ctx: ctx, done: (*ctx).Done(), env: env}
+}
+
+// step counts a visit of the node at pos, checking whether the evaluation is
+// interrupted or out of steps.
+func (ev *evaluator) step(pos Position) error {
+	if ev.done != nil {
+		select {
+		case <-ev.done:
+			return ErrInterrupted{pos, (*ev.ctx).Err()}
+		default:
+		}
+	}
+	if ev.steps++; ev.env.MaxSteps > 0 && ev.steps > ev.env.MaxSteps {
+		return ErrStepLimit{pos}
 	}
+	return nil
+}
 
-	binary struct {
-		op   token
-		l, r Expr
+// visit evaluates e. It dispatches on the type of e rather than calling a
+// method of Expr, so that ev does not escape to the heap.
+func (ev *evaluator) visit(e Expr) (float64, error) {
+	if err := ev.step(e.Pos()); err != nil {
+		return 0, err
+	}
+	switch e := e.(type) {
+	case Variable:
+		return e.eval(ev)
+	case Constant:
+		return e.val, nil
+	case Literal:
+		return e.val, nil
+	case Angle:
+		return e.eval(ev)
+	case Unary:
+		return e.eval(ev)
+	case Binary:
+		return e.eval(ev)
+	case Call:
+		return e.eval(ev)
 	}
+	panic("Not handling all possible cases")
+}
 
-	variable token
+// checkOverflow reports an ErrOverflow at pos if val overflowed although
+// none of operands is infinite.
+func (ev *evaluator) checkOverflow(pos Position, val float64, operands ...float64) error {
+	if !ev.env.CheckOverflow || !math.IsInf(val, 0) {
+		return nil
+	}
+	for _, x := range operands {
+		if math.IsInf(x, 0) {
+			return nil
+		}
+	}
+	return ErrOverflow{pos}
+}
 
-	literal token
+func (v Variable) Val(env Env) (float64, error) { return v.ValContext(context.Background(), env) }
+func (c Constant) Val(env Env) (float64, error) { return c.ValContext(context.Background(), env) }
+func (l Literal) Val(env Env) (float64, error)  { return l.ValContext(context.Background(), env) }
+func (a Angle) Val(env Env) (float64, error)    { return a.ValContext(context.Background(), env) }
+func (u Unary) Val(env Env) (float64, error)    { return u.ValContext(context.Background(), env) }
+func (b Binary) Val(env Env) (float64, error)   { return b.ValContext(context.Background(), env) }
+func (c Call) Val(env Env) (float64, error)     { return c.ValContext(context.Background(), env) }
 
-	call struct {
-		token
-		args []Expr
+func (v Variable) ValContext(ctx context.Context, env Env) (float64, error) {
+	ev := newEvaluator(&ctx, &env)
+	if err := ev.step(v.Pos()); err != nil {
+		return 0, err
 	}
-)
+	return v.eval(&ev)
+}
+
+func (c Constant) ValContext(ctx context.Context, env Env) (float64, error) {
+	ev := newEvaluator(&ctx, &env)
+	if err := ev.step(c.Pos()); err != nil {
+		return 0, err
+	}
+	return c.eval(&ev)
+}
+
+func (l Literal) ValContext(ctx context.Context, env Env) (float64, error) {
+	ev := newEvaluator(&ctx, &env)
+	if err := ev.step(l.Pos()); err != nil {
+		return 0, err
+	}
+	return l.eval(&ev)
+}
+
+func (a Angle) ValContext(ctx context.Context, env Env) (float64, error) {
+	ev := newEvaluator(&ctx, &env)
+	if err := ev.step(a.Pos()); err != nil {
+		return 0, err
+	}
+	return a.eval(&ev)
+}
+
+func (u Unary) ValContext(ctx context.Context, env Env) (float64, error) {
+	ev := newEvaluator(&ctx, &env)
+	if err := ev.step(u.Pos()); err != nil {
+		return 0, err
+	}
+	return u.eval(&ev)
+}
+
+func (b Binary) ValContext(ctx context.Context, env Env) (float64, error) {
+	ev := newEvaluator(&ctx, &env)
+	if err := ev.step(b.Pos()); err != nil {
+		return 0, err
+	}
+	return b.eval(&ev)
+}
 
-func (v variable) Val(env Env) (val float64, err error) {
+func (c Call) ValContext(ctx context.Context, env Env) (float64, error) {
+	ev := newEvaluator(&ctx, &env)
+	if err := ev.step(c.Pos()); err != nil {
+		return 0, err
+	}
+	return c.eval(&ev)
+}
+
+func (v Variable) eval(ev *evaluator) (val float64, err error) {
 	var ok bool
-	if val, ok = env.Vars[v.txt]; !ok {
+	if val, ok = ev.env.lookup(v.txt); !ok {
 		err = ErrUndeclaredVar{v.Position, v.txt}
 	}
 	return
 }
 
-func (l literal) Val(_ Env) (val float64, _ error) {
-	switch l.typ {
-	case integer, float:
-		val, _ = strconv.ParseFloat(l.txt, 64)
-	case binLiteral, octLiteral, hexLiteral:
-		t, _ := strconv.ParseInt(l.txt, 0, 64)
-		val = float64(t)
+func (c Constant) eval(_ *evaluator) (float64, error) { return c.val, nil }
+
+func (l Literal) eval(_ *evaluator) (float64, error) { return l.val, nil }
+
+func (a Angle) eval(ev *evaluator) (val float64, err error) {
+	if val, err = a.lit.eval(ev); err != nil {
+		return
 	}
-	return
+	return a.unit.convert(val, ev.env.AngleMode), nil
 }
 
-func (u unary) Val(env Env) (val float64, err error) {
-	if val, err = u.expr.Val(env); err != nil {
+func (u Unary) eval(ev *evaluator) (val float64, err error) {
+	if val, err = ev.visit(u.expr); err != nil {
 		return
 	}
 	switch u.op.typ {
@@ -63,12 +173,12 @@ func (u unary) Val(env Env) (val float64, err error) {
 	return
 }
 
-func (b binary) Val(env Env) (val float64, err error) {
+func (b Binary) eval(ev *evaluator) (val float64, err error) {
 	var left, right float64
-	if left, err = b.l.Val(env); err != nil {
+	if left, err = ev.visit(b.l); err != nil {
 		return
 	}
-	if right, err = b.r.Val(env); err != nil {
+	if right, err = ev.visit(b.r); err != nil {
 		return
 	}
 
@@ -88,41 +198,92 @@ func (b binary) Val(env Env) (val float64, err error) {
 	case doubleStar:
 		val = math.Pow(left, right)
 	}
+	if !b.dividesByZero(left, right) {
+		err = ev.checkOverflow(b.op.Position, val, left, right)
+	}
 	return
 }
 
-func (c call) Val(env Env) (val float64, err error) {
-	fun, ok := env.Funcs[c.txt]
+// dividesByZero reports whether b divides by zero, which yields an infinity
+// or NaN without overflowing: a zero divisor, or zero raised to a negative
+// power.
+func (b Binary) dividesByZero(left, right float64) bool {
+	switch b.op.typ {
+	case slash, doubleSlash, percent:
+		return right == 0
+	case doubleStar:
+		return left == 0 && right < 0
+	}
+	return false
+}
+
+func (c Call) eval(ev *evaluator) (val float64, err error) {
+	fun, ok := ev.env.lookupFunc(c.txt)
 	if !ok {
 		err = ErrUndeclaredFunc{c.token.Position, c.txt}
 		return
 	}
 
-	ftype := reflect.TypeOf(fun)
+	f, err := toFunction(c.txt, fun)
+	if err != nil {
+		return
+	}
 
-	argc := ftype.NumIn()
-	if ftype.IsVariadic() {
-		argc--
+	if ev.callDepth++; ev.env.MaxCallDepth > 0 && ev.callDepth > ev.env.MaxCallDepth {
+		err = ErrCallDepthLimit{c.token.Position, c.txt}
+		return
 	}
+	defer func() { ev.callDepth-- }()
 
-	if len(c.args) < argc {
+	if o, ok := f.(overloaded); ok {
+		if f, ok = o.pick(len(c.args)); !ok {
+			err = ErrNoOverload{c.token.Position, c.txt, len(c.args), o.signatures(c.txt)}
+			return
+		}
+	}
+
+	min, max := f.arity()
+	if len(c.args) < min {
 		err = ErrTooFewArgsToCall{c.token.Position, c.txt}
 		return
-	} else if len(c.args) > argc && !ftype.IsVariadic() {
+	} else if len(c.args) > max && max >= 0 {
 		err = ErrTooManyArgsToCall{c.token.Position, c.txt}
 		return
 	}
 
-	args := make([]reflect.Value, len(c.args))
+	args := make([]float64, len(c.args))
 	for i, arg := range c.args {
-		if val, err = arg.Val(env); err != nil {
+		if args[i], err = ev.visit(arg); err != nil {
 			return
 		}
-		args[i] = reflect.ValueOf(val)
 	}
 
-	results := reflect.ValueOf(fun).Call(args)
-	val = results[0].Float()
-
+	if val, err = c.invoke(ev, f, args); err != nil {
+		switch e := err.(type) {
+		case recovered:
+			err = e.ErrFuncPanicked
+		case conversionError:
+			err = ErrArgConversion{c.token.Position, c.txt, e.n, e.val, e.typ.String()}
+		default:
+			err = ErrFuncFailed{c.token.Position, c.txt, err}
+		}
+	}
 	return
 }
+
+// recovered is an ErrFuncPanicked raised by invoke, told apart from the
+// errors f returns, which may be ErrFuncPanicked of nested evaluations.
+type recovered struct{ ErrFuncPanicked }
+
+// invoke calls f, turning a panic in it into an ErrFuncPanicked unless
+// Env.KeepPanics is set.
+func (c Call) invoke(ev *evaluator, f function, args []float64) (val float64, err error) {
+	if !ev.env.KeepPanics {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered{ErrFuncPanicked{c.token.Position, c.txt, r, debug.Stack()}}
+			}
+		}()
+	}
+	return f.call(*ev.ctx, *ev.env, args)
+}
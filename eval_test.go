@@ -1,6 +1,7 @@
 package sec
 
 import (
+	"context"
 	"errors"
 	"testing"
 )
@@ -9,7 +10,7 @@ func TestEvalUndeclaredVar(t *testing.T) {
 	const text = "balah"
 
 	var env Env
-	variable := variable(token{txt: text})
+	variable := Variable(token{txt: text})
 
 	var uerr ErrUndeclaredVar
 	if _, err := variable.Val(env); errors.As(err, &uerr) {
@@ -32,7 +33,7 @@ func TestEvalVariable(t *testing.T) {
 		},
 	}
 	for txt, val := range env.Vars {
-		variable := variable(token{txt: txt})
+		variable := Variable(token{txt: txt})
 		v, err := variable.Val(env)
 		if err != nil {
 			t.Fatal("expect no error")
@@ -56,7 +57,7 @@ func TestEvalLiteral(t *testing.T) {
 
 	for typ, pairs := range pairsGroup {
 		for _, pair := range pairs {
-			literal := literal(token{
+			literal := newLiteral(token{
 				typ: typ,
 				txt: pair.txt,
 			})
@@ -68,3 +69,91 @@ func TestEvalLiteral(t *testing.T) {
 		}
 	}
 }
+
+func TestValContext(t *testing.T) {
+	type key struct{}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, 42.0))
+	env := Env{Funcs: Funcs{
+		"answer": func(ctx context.Context) float64 { return ctx.Value(key{}).(float64) },
+		"cancel": func(ctx context.Context, x float64) float64 {
+			cancel()
+			return x
+		},
+	}}
+	if err := env.Funcs.Check(); err != nil {
+		t.Fatal("expect no error, got", err)
+	}
+
+	expr, _ := Parse("answer() + 1")
+	if val, err := expr.ValContext(ctx, env); err != nil {
+		t.Fatal(err)
+	} else if val != 43 {
+		t.Fatal("expect 43, got", val)
+	}
+
+	expr, _ = Parse("cancel(1) + answer()")
+	_, err := expr.ValContext(ctx, env)
+	var ierr ErrInterrupted
+	if !errors.As(err, &ierr) {
+		t.Fatal("expect ErrInterrupted error, got", err)
+	} else if ierr.Position != (Position{1, 13}) {
+		t.Fatal("position not correct:", ierr.Position)
+	} else if !errors.Is(err, context.Canceled) {
+		t.Fatal("expect context.Canceled")
+	}
+}
+
+func TestEvalLimits(t *testing.T) {
+	env := Env{Funcs: Funcs{"f": Func1(func(x float64) float64 { return x })}}
+
+	expr, _ := Parse("1 + 2 + 3")
+	env.MaxSteps = 5
+	if _, err := expr.Val(env); err != nil {
+		t.Fatal("expect no error, got", err)
+	}
+	env.MaxSteps = 4
+	if _, err := expr.Val(env); !errors.As(err, &ErrStepLimit{}) {
+		t.Fatal("expect ErrStepLimit error, got", err)
+	}
+	env.MaxSteps = 0
+
+	expr, _ = Parse("f(f(f(1)))")
+	env.MaxCallDepth = 3
+	if _, err := expr.Val(env); err != nil {
+		t.Fatal("expect no error, got", err)
+	}
+	env.MaxCallDepth = 2
+	var cerr ErrCallDepthLimit
+	if _, err := expr.Val(env); !errors.As(err, &cerr) {
+		t.Fatal("expect ErrCallDepthLimit error, got", err)
+	} else if cerr.Position != (Position{1, 5}) {
+		t.Fatal("position not correct:", cerr.Position)
+	}
+
+	expr, _ = Parse("10 ** 10 ** 10 ** 10")
+	if _, err := expr.Val(env); err != nil {
+		t.Fatal("expect no error, got", err)
+	}
+	env.CheckOverflow = true
+	var oerr ErrOverflow
+	if _, err := expr.Val(env); !errors.As(err, &oerr) {
+		t.Fatal("expect ErrOverflow error, got", err)
+	} else if oerr.Position != (Position{1, 16}) {
+		t.Fatal("position not correct:", oerr.Position)
+	}
+	for _, src := range []string{"1 / 0", "-1 // 0", "1 % 0", "0 ** -1"} {
+		expr, _ = Parse(src)
+		if _, err := expr.Val(env); err != nil {
+			t.Fatal(src, "expect no error, got", err)
+		}
+	}
+}
+
+func BenchmarkEvalArithmetic(b *testing.B) {
+	env := Env{Vars: Vars{"x": 3}}
+	expr, _ := Parse("x * 3 + 2 - x / 4")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		expr.Val(env)
+	}
+}
@@ -0,0 +1,249 @@
+package sec
+
+import (
+	"math"
+)
+
+// FinanceFuncs is a set of financial functions. Their names, argument order,
+// optional arguments and sign conventions follow the spreadsheet functions
+// of the same names in Excel and LibreOffice: money paid out is negative,
+// money received is positive. Functions taking cash flows and dates, like
+// xnpv, take all values first and then all dates, dates are serial day
+// numbers. Invalid input yields NaN, irr, rate and xirr fail with
+// ErrNoConvergence when no solution is found.
+var FinanceFuncs = Funcs{
+	"pmt":  pure(3, 5, pmt),
+	"ipmt": pure(4, 6, ipmt),
+	"ppmt": pure(4, 6, ppmt),
+	"fv":   pure(3, 5, fv),
+	"pv":   pure(3, 5, pv),
+	"nper": pure(3, 5, nper),
+	"rate": pure(3, 6, rate),
+	"npv":  pure(2, -1, npv),
+	"irr":  pure(1, -1, irr),
+	"xnpv": pure(3, -1, xnpv),
+	"xirr": pure(2, -1, xirr),
+	"sln":  pure(3, 3, sln),
+	"ddb":  pure(4, 5, ddb),
+}
+
+// financeInfo documents FinanceFuncs.
+var financeInfo = map[string]FuncInfo{
+	"pmt":  doc("rate, nper, pv, [fv], [type]", "pmt returns the payment per period of a loan or an annuity.", "pmt(0.05/12, 360, 200000)"),
+	"ipmt": doc("rate, per, nper, pv, [fv], [type]", "ipmt returns the interest part of the payment of period per.", "ipmt(0.05/12, 1, 360, 200000)"),
+	"ppmt": doc("rate, per, nper, pv, [fv], [type]", "ppmt returns the principal part of the payment of period per.", "ppmt(0.05/12, 1, 360, 200000)"),
+	"fv":   doc("rate, nper, pmt, [pv], [type]", "fv returns the future value of an investment.", "fv(0.05/12, 120, -100)"),
+	"pv":   doc("rate, nper, pmt, [fv], [type]", "pv returns the present value of an investment.", "pv(0.05/12, 360, -1073.64)"),
+	"nper": doc("rate, pmt, pv, [fv], [type]", "nper returns the number of periods of an investment.", "nper(0.05/12, -1073.64, 200000)"),
+	"rate": doc("nper, pmt, pv, [fv], [type], [guess]", "rate returns the interest rate per period of an annuity.", "rate(360, -1073.64, 200000)"),
+	"npv":  doc("rate, values", "npv returns the net present value of values paid at the end of consecutive periods.", "npv(0.1, -1000, 300, 400, 500)"),
+	"irr":  doc("values", "irr returns the internal rate of return of values paid at the end of consecutive periods.", "irr(-1000, 300, 400, 500)"),
+	"xnpv": doc("rate, values..., dates", "xnpv returns the net present value of values paid at dates.", "xnpv(0.1, -1000, 1100, 0, 365)"),
+	"xirr": doc("values..., dates", "xirr returns the internal rate of return of values paid at dates.", "xirr(-1000, 1100, 0, 365)"),
+	"sln":  doc("cost, salvage, life", "sln returns the straight-line depreciation of an asset for one period.", "sln(10000, 1000, 5)"),
+	"ddb":  doc("cost, salvage, life, period, [factor]", "ddb returns the depreciation of an asset for period by the double-declining balance method.", "ddb(10000, 1000, 5, 1)"),
+}
+
+// LoadFinance adds FinanceFuncs to e. Existing names are overwritten.
+func (e *Env) LoadFinance() { e.load("finance", nil, FinanceFuncs, financeInfo) }
+
+const (
+	maxIterations = 100
+	tolerance     = 1e-10
+)
+
+// opt returns the ith argument, or def if it was omitted.
+func opt(args []float64, i int, def float64) float64 {
+	if i < len(args) {
+		return args[i]
+	}
+	return def
+}
+
+// annuity returns the future value factor of an annuity, the value that
+// pmt*annuity(...) contributes to the future value.
+func annuity(rate, nper, typ float64) float64 {
+	if rate == 0 {
+		return nper
+	}
+	return (1 + rate*typ) * (math.Pow(1+rate, nper) - 1) / rate
+}
+
+// pmt(rate, nper, pv[, fv[, type]])
+func pmt(args []float64) (float64, error) {
+	return pmtOf(args[0], args[1], args[2], opt(args, 3, 0), opt(args, 4, 0)), nil
+}
+
+func pmtOf(rate, nper, pv, fv, typ float64) float64 {
+	return -(fv + pv*math.Pow(1+rate, nper)) / annuity(rate, nper, typ)
+}
+
+// fv(rate, nper, pmt[, pv[, type]])
+func fv(args []float64) (float64, error) {
+	return fvOf(args[0], args[1], args[2], opt(args, 3, 0), opt(args, 4, 0)), nil
+}
+
+func fvOf(rate, nper, pmt, pv, typ float64) float64 {
+	return -(pv*math.Pow(1+rate, nper) + pmt*annuity(rate, nper, typ))
+}
+
+// pv(rate, nper, pmt[, fv[, type]])
+func pv(args []float64) (float64, error) {
+	rate, nper, pmt := args[0], args[1], args[2]
+	fv, typ := opt(args, 3, 0), opt(args, 4, 0)
+	return -(fv + pmt*annuity(rate, nper, typ)) / math.Pow(1+rate, nper), nil
+}
+
+// nper(rate, pmt, pv[, fv[, type]])
+func nper(args []float64) (float64, error) {
+	rate, pmt, pv := args[0], args[1], args[2]
+	fv, typ := opt(args, 3, 0), opt(args, 4, 0)
+	if rate == 0 {
+		return -(pv + fv) / pmt, nil
+	}
+	p := pmt * (1 + rate*typ)
+	return math.Log((p-fv*rate)/(p+pv*rate)) / math.Log1p(rate), nil
+}
+
+// ipmt(rate, per, nper, pv[, fv[, type]])
+func ipmt(args []float64) (float64, error) {
+	return ipmtOf(args[0], args[1], args[2], args[3], opt(args, 4, 0), opt(args, 5, 0)), nil
+}
+
+func ipmtOf(rate, per, nper, pv, fv, typ float64) float64 {
+	if per < 1 || per > nper {
+		return math.NaN()
+	}
+	if typ != 0 && per == 1 {
+		return 0
+	}
+	pmt := pmtOf(rate, nper, pv, fv, typ)
+	val := fvOf(rate, per-1, pmt, pv, typ) * rate
+	if typ != 0 {
+		val /= 1 + rate
+	}
+	return val
+}
+
+// ppmt(rate, per, nper, pv[, fv[, type]])
+func ppmt(args []float64) (float64, error) {
+	rate, per, nper, pv := args[0], args[1], args[2], args[3]
+	fv, typ := opt(args, 4, 0), opt(args, 5, 0)
+	return pmtOf(rate, nper, pv, fv, typ) - ipmtOf(rate, per, nper, pv, fv, typ), nil
+}
+
+// newton finds a root of f near guess with Newton's method, df is the
+// derivative of f.
+func newton(guess float64, f, df func(float64) float64) (float64, error) {
+	x := guess
+	for i := 0; i < maxIterations; i++ {
+		y, dy := f(x), df(x)
+		if dy == 0 || math.IsNaN(y) || math.IsNaN(dy) {
+			break
+		}
+		next := x - y/dy
+		if math.Abs(next-x) < tolerance {
+			return next, nil
+		}
+		x = next
+	}
+	return 0, ErrNoConvergence{}
+}
+
+// rate(nper, pmt, pv[, fv[, type[, guess]]])
+func rate(args []float64) (float64, error) {
+	nper, pmt, pv := args[0], args[1], args[2]
+	fv, typ, guess := opt(args, 3, 0), opt(args, 4, 0), opt(args, 5, 0.1)
+
+	// f is the future value of all cash flows, which must be zero.
+	f := func(r float64) float64 {
+		return pv*math.Pow(1+r, nper) + pmt*annuity(r, nper, typ) + fv
+	}
+	df := func(r float64) float64 {
+		const h = 1e-7
+		return (f(r+h) - f(r-h)) / (2 * h)
+	}
+	return newton(guess, f, df)
+}
+
+// npv(rate, value1[, value2...])
+func npv(args []float64) (val float64, _ error) {
+	rate := args[0]
+	for i, v := range args[1:] {
+		val += v / math.Pow(1+rate, float64(i+1))
+	}
+	return
+}
+
+// irr(value1[, value2...])
+func irr(args []float64) (float64, error) {
+	f := func(r float64) (val float64) {
+		for i, v := range args {
+			val += v / math.Pow(1+r, float64(i))
+		}
+		return
+	}
+	df := func(r float64) (val float64) {
+		for i, v := range args {
+			val -= float64(i) * v / math.Pow(1+r, float64(i+1))
+		}
+		return
+	}
+	return newton(0.1, f, df)
+}
+
+// xnpvOf returns the net present value of values paid at dates.
+func xnpvOf(rate float64, values, dates []float64) (val float64) {
+	for i, v := range values {
+		val += v / math.Pow(1+rate, (dates[i]-dates[0])/365)
+	}
+	return
+}
+
+// xnpv(rate, value1, ..., valueN, date1, ..., dateN)
+func xnpv(args []float64) (float64, error) {
+	values, dates, ok := halves(args[1:])
+	if !ok {
+		return math.NaN(), nil
+	}
+	return xnpvOf(args[0], values, dates), nil
+}
+
+// xirr(value1, ..., valueN, date1, ..., dateN)
+func xirr(args []float64) (float64, error) {
+	values, dates, ok := halves(args)
+	if !ok {
+		return math.NaN(), nil
+	}
+	f := func(r float64) float64 { return xnpvOf(r, values, dates) }
+	df := func(r float64) (val float64) {
+		for i, v := range values {
+			t := (dates[i] - dates[0]) / 365
+			val -= t * v / math.Pow(1+r, t+1)
+		}
+		return
+	}
+	return newton(0.1, f, df)
+}
+
+// sln(cost, salvage, life)
+func sln(args []float64) (float64, error) {
+	cost, salvage, life := args[0], args[1], args[2]
+	return (cost - salvage) / life, nil
+}
+
+// ddb(cost, salvage, life, period[, factor])
+func ddb(args []float64) (float64, error) {
+	cost, salvage, life, period := args[0], args[1], args[2], args[3]
+	factor := opt(args, 4, 2)
+	if cost < 0 || salvage < 0 || life <= 0 || period < 1 || period > life || factor <= 0 {
+		return math.NaN(), nil
+	}
+
+	var dep, total float64
+	for p := 1.0; p <= period; p++ {
+		dep = math.Min((cost-total)*factor/life, math.Max(cost-salvage-total, 0))
+		total += dep
+	}
+	return dep, nil
+}
@@ -0,0 +1,71 @@
+package sec
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFinanceFuncsCheck(t *testing.T) {
+	if err := FinanceFuncs.Check(); err != nil {
+		t.Fatal("expect no error, got", err)
+	}
+}
+
+func TestLoadFinance(t *testing.T) {
+	var env Env
+	env.LoadFinance()
+
+	// expected values are taken from the Excel documentation
+	cases := map[string]float64{
+		"pmt(0.08/12, 10, 10000)":                              -1037.03,
+		"fv(0.06/12, 10, -200, -500, 1)":                       2581.40,
+		"pv(0.08/12, 12*20, 500, 0, 0)":                        -59777.15,
+		"nper(0.12/12, -100, -1000, 10000, 1)":                 59.67,
+		"ipmt(0.1/12, 1, 3*12, 8000)":                          -66.67,
+		"ppmt(0.1/12, 1, 2*12, 2000)":                          -75.62,
+		"rate(4*12, -200, 8000) * 100":                         0.77,
+		"npv(0.1, -10000, 3000, 4200, 6800)":                   1188.44,
+		"irr(-70000, 12000, 15000, 18000, 21000, 26000) * 100": 8.66,
+		"sln(30000, 7500, 10)":                                 2250,
+		"ddb(2400, 300, 10*12, 1)":                             40,
+		"ddb(2400, 300, 10, 10)":                               22.12,
+		"ddb(2400, 300, 10, 2, 1.5)":                           306,
+		"xnpv(0.09, -10000, 2750, 4250, 3250, 2750, " +
+			"39448, 39508, 39751, 39859, 39904)": 2086.65,
+		"xirr(-10000, 2750, 4250, 3250, 2750, " +
+			"39448, 39508, 39751, 39859, 39904) * 100": 37.34,
+	}
+
+	expectVals(t, env, 0.005, cases)
+}
+
+func TestFinanceNoConvergence(t *testing.T) {
+	var env Env
+	env.LoadFinance()
+
+	expr, err := Parse("irr(1, 2, 3)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ferr ErrFuncFailed
+	if _, err := expr.Val(env); !errors.Is(err, ErrNoConvergence{}) {
+		t.Fatal("expect ErrNoConvergence error, got", err)
+	} else if !errors.As(err, &ferr) || ferr.Name != "irr" {
+		t.Fatal("function name not correct")
+	}
+}
+
+func TestFinanceArgCount(t *testing.T) {
+	var env Env
+	env.LoadFinance()
+
+	expr, _ := Parse("pmt(0.1, 10)")
+	if _, err := expr.Val(env); !errors.As(err, &ErrTooFewArgsToCall{}) {
+		t.Fatal("expect ErrTooFewArgsToCall error, got", err)
+	}
+
+	expr, _ = Parse("sln(1, 2, 3, 4)")
+	if _, err := expr.Val(env); !errors.As(err, &ErrTooManyArgsToCall{}) {
+		t.Fatal("expect ErrTooManyArgsToCall error, got", err)
+	}
+}
@@ -0,0 +1,337 @@
+package sec
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"sync"
+)
+
+// Typed host functions. Unlike arbitrary Go functions in Funcs, they are
+// called without reflection.
+type (
+	Func0 func() float64
+	Func1 func(x float64) float64
+	Func2 func(x, y float64) float64
+	Func3 func(x, y, z float64) float64
+	// FuncN takes any number of arguments.
+	FuncN func(args []float64) float64
+
+	// Overloads holds implementations of one function, each of the forms
+	// accepted in Funcs. A call is dispatched to the first implementation
+	// accepting its number of arguments.
+	Overloads []interface{}
+)
+
+type (
+	// function is the form every value in Funcs is called in.
+	function interface {
+		// arity returns the least and the most number of arguments the
+		// function accepts, a negative max means no limit.
+		arity() (min, max int)
+		call(ctx context.Context, env Env, args []float64) (float64, error)
+	}
+
+	// builtin is a function shipped with sec that may fail. It accepts at
+	// least min and at most max arguments, a negative max means no limit.
+	builtin struct {
+		min, max int
+		fn       func(env Env, args []float64) (float64, error)
+	}
+
+	// overloaded is the function form of Overloads.
+	overloaded []function
+
+	// reflected is a checked Go function called through reflection.
+	reflected struct {
+		fn reflect.Value
+		*funcShape
+	}
+
+	// funcShape is what a checked Go function type tells about calling it.
+	funcShape struct {
+		params   []reflect.Type // parameter types, not counting the context
+		rest     reflect.Type   // element type of the variadic parameter, or nil
+		fallible bool           // whether it returns an error as the second result
+		withCtx  bool           // whether it takes a context.Context as the first parameter
+	}
+
+	// conversionError reports that the Nth argument can not be converted to
+	// the type of its parameter.
+	conversionError struct {
+		n   int
+		val float64
+		typ reflect.Type
+	}
+)
+
+func (e conversionError) Error() string { return "argument conversion failed" }
+
+// pure makes a builtin whose result depends on its arguments only.
+func pure(min, max int, fn func(args []float64) (float64, error)) builtin {
+	return builtin{min, max, func(_ Env, args []float64) (float64, error) {
+		return fn(args)
+	}}
+}
+
+// variadic makes a pure builtin of fn, which takes at least min arguments.
+func variadic(min int, fn FuncN) builtin {
+	return pure(min, -1, func(args []float64) (float64, error) {
+		return fn(args), nil
+	})
+}
+
+func (Func0) arity() (min, max int) { return 0, 0 }
+func (Func1) arity() (min, max int) { return 1, 1 }
+func (Func2) arity() (min, max int) { return 2, 2 }
+func (Func3) arity() (min, max int) { return 3, 3 }
+func (FuncN) arity() (min, max int) { return 0, -1 }
+
+func (b builtin) arity() (min, max int) { return b.min, b.max }
+
+func (o overloaded) arity() (min, max int) {
+	min = -1
+	for _, f := range o {
+		fmin, fmax := f.arity()
+		if min < 0 || fmin < min {
+			min = fmin
+		}
+		if max >= 0 && (fmax < 0 || fmax > max) {
+			max = fmax
+		}
+	}
+	return
+}
+
+func (r reflected) arity() (min, max int) {
+	if r.rest != nil {
+		return len(r.params), -1
+	}
+	return len(r.params), len(r.params)
+}
+
+func (f Func0) call(_ context.Context, _ Env, _ []float64) (float64, error) { return f(), nil }
+func (f Func1) call(_ context.Context, _ Env, args []float64) (float64, error) {
+	return f(args[0]), nil
+}
+func (f Func2) call(_ context.Context, _ Env, args []float64) (float64, error) {
+	return f(args[0], args[1]), nil
+}
+func (f Func3) call(_ context.Context, _ Env, args []float64) (float64, error) {
+	return f(args[0], args[1], args[2]), nil
+}
+func (f FuncN) call(_ context.Context, _ Env, args []float64) (float64, error) { return f(args), nil }
+
+func (b builtin) call(_ context.Context, env Env, args []float64) (float64, error) {
+	return b.fn(env, args)
+}
+
+func (o overloaded) call(ctx context.Context, env Env, args []float64) (float64, error) {
+	f, ok := o.pick(len(args))
+	if !ok {
+		return 0, ErrNoOverload{N: len(args), Signatures: o.signatures("")}
+	}
+	return f.call(ctx, env, args)
+}
+
+// pick returns the first implementation accepting n arguments.
+func (o overloaded) pick(n int) (f function, ok bool) {
+	for _, f = range o {
+		if min, max := f.arity(); n >= min && (n <= max || max < 0) {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// signatures returns the signatures of the implementations named name.
+func (o overloaded) signatures(name string) []string {
+	sigs := make([]string, len(o))
+	for i, f := range o {
+		sigs[i] = signature(name, f, nil)
+	}
+	return sigs
+}
+
+func (r reflected) call(ctx context.Context, _ Env, args []float64) (float64, error) {
+	in := make([]reflect.Value, 0, len(args)+1)
+	if r.withCtx {
+		in = append(in, reflect.ValueOf(&ctx).Elem())
+	}
+	for i, arg := range args {
+		typ := r.rest
+		if i < len(r.params) {
+			typ = r.params[i]
+		}
+		v, ok := toValue(arg, typ)
+		if !ok {
+			return 0, conversionError{i + 1, arg, typ}
+		}
+		in = append(in, v)
+	}
+	out := r.fn.Call(in)
+	if r.fallible && !out[1].IsNil() {
+		return 0, out[1].Interface().(error)
+	}
+	return fromValue(out[0]), nil
+}
+
+// isNumeric reports whether values of t can be converted from and to
+// float64.
+func isNumeric(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Float64, reflect.Float32,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Bool:
+		return true
+	}
+	return false
+}
+
+// toValue converts x to a value of the numeric type typ. It fails if x has a
+// fraction or is out of range for integer types, or is neither 0 nor 1 for
+// bool.
+func toValue(x float64, typ reflect.Type) (v reflect.Value, ok bool) {
+	if typ == float64Type {
+		return reflect.ValueOf(x), true
+	}
+
+	v = reflect.New(typ).Elem()
+	switch typ.Kind() {
+	case reflect.Float64:
+		v.SetFloat(x)
+	case reflect.Float32:
+		if math.Abs(x) > math.MaxFloat32 && !math.IsInf(x, 0) {
+			return v, false
+		}
+		v.SetFloat(x)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !isInteger(x) || x < math.MinInt64 || x >= -math.MinInt64 || v.OverflowInt(int64(x)) {
+			return v, false
+		}
+		v.SetInt(int64(x))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if !isInteger(x) || x < 0 || x >= 1<<64 || v.OverflowUint(uint64(x)) {
+			return v, false
+		}
+		v.SetUint(uint64(x))
+	case reflect.Bool:
+		if x != 0 && x != 1 {
+			return v, false
+		}
+		v.SetBool(x == 1)
+	default:
+		return v, false
+	}
+	return v, true
+}
+
+// fromValue converts the numeric value v to float64, true is converted to 1.
+func fromValue(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Bool:
+		if v.Bool() {
+			return 1
+		}
+		return 0
+	}
+	return v.Float()
+}
+
+var (
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	float64Type = reflect.TypeOf(float64(0))
+)
+
+// toFunction checks fun, the value of name in Funcs, and converts it into a
+// function. The parameters and the result of a Go function must be of
+// numeric or bool types, it may take a context.Context as its first
+// parameter, and may return an error as its second result.
+func toFunction(name string, fun interface{}) (function, error) {
+	switch f := fun.(type) {
+	case function:
+		return f, nil
+	// Go functions of float64s are converted to the typed function types,
+	// which are called without reflection
+	case func() float64:
+		return Func0(f), nil
+	case func(float64) float64:
+		return Func1(f), nil
+	case func(float64, float64) float64:
+		return Func2(f), nil
+	case func(float64, float64, float64) float64:
+		return Func3(f), nil
+	case func(...float64) float64:
+		return FuncN(func(args []float64) float64 { return f(args...) }), nil
+	case Overloads:
+		if len(f) == 0 {
+			return nil, ErrNotFunction{name}
+		}
+		o := make(overloaded, len(f))
+		for i, fun := range f {
+			var err error
+			if o[i], err = toFunction(name, fun); err != nil {
+				return nil, err
+			}
+		}
+		return o, nil
+	}
+
+	funcType := reflect.TypeOf(fun)
+	if funcType == nil || funcType.Kind() != reflect.Func {
+		return nil, ErrNotFunction{name}
+	}
+	shape, err := shapeOf(name, funcType)
+	if err != nil {
+		return nil, err
+	}
+	return reflected{reflect.ValueOf(fun), shape}, nil
+}
+
+// shapes caches the shapes of checked function types, as raw Go functions in
+// Funcs are converted on every call.
+var shapes sync.Map // map[reflect.Type]*funcShape
+
+// shapeOf checks funcType, the type of name in Funcs, and returns its shape.
+func shapeOf(name string, funcType reflect.Type) (*funcShape, error) {
+	if shape, ok := shapes.Load(funcType); ok {
+		return shape.(*funcShape), nil
+	}
+
+	numIn, numOut := funcType.NumIn(), funcType.NumOut()
+	switch {
+	case numOut == 0:
+		return nil, ErrFuncNoReturnVal{name}
+	case numOut > 2, numOut == 2 && funcType.Out(1) != errorType:
+		return nil, ErrFuncReturnTooManyVal{name}
+	case !isNumeric(funcType.Out(0)):
+		return nil, ErrReturnValNotFloat64{name}
+	}
+
+	shape := &funcShape{fallible: numOut == 2}
+	first := 0
+	if numIn > 0 && funcType.In(0) == contextType {
+		shape.withCtx, first = true, 1
+	}
+	if funcType.IsVariadic() {
+		if shape.rest = funcType.In(numIn - 1).Elem(); !isNumeric(shape.rest) {
+			return nil, ErrParamNotFloat64{name, numIn}
+		}
+		numIn--
+	}
+	for i := first; i < numIn; i++ {
+		if !isNumeric(funcType.In(i)) {
+			return nil, ErrParamNotFloat64{name, i + 1}
+		}
+		shape.params = append(shape.params, funcType.In(i))
+	}
+
+	shapes.Store(funcType, shape)
+	return shape, nil
+}
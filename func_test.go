@@ -0,0 +1,284 @@
+package sec
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestTypedFuncs(t *testing.T) {
+	env := Env{Funcs: Funcs{
+		"zero": Func0(func() float64 { return 0 }),
+		"neg":  Func1(func(x float64) float64 { return -x }),
+		"pow":  Func2(math.Pow),
+		"fma":  Func3(func(x, y, z float64) float64 { return x*y + z }),
+		"sum": FuncN(func(args []float64) (s float64) {
+			for _, arg := range args {
+				s += arg
+			}
+			return
+		}),
+	}}
+
+	cases := map[string]float64{
+		"zero()":                 0,
+		"neg(2)":                 -2,
+		"pow(2, 10)":             1024,
+		"fma(2, 3, 4)":           10,
+		"sum()":                  0,
+		"sum(1, 2, 3, 4)":        10,
+		"neg(sum(pow(2, 2), 1))": -5,
+	}
+	for src, want := range cases {
+		expr, err := Parse(src)
+		if err != nil {
+			t.Fatal(src, err)
+		}
+		if got, err := expr.Val(env); err != nil {
+			t.Fatal(src, err)
+		} else if got != want {
+			t.Fatalf("%s: expect %v, got %v", src, want, got)
+		}
+	}
+
+	expr, _ := Parse("pow(1)")
+	if _, err := expr.Val(env); err == nil {
+		t.Fatal("expect ErrTooFewArgsToCall error")
+	} else if _, ok := err.(ErrTooFewArgsToCall); !ok {
+		t.Fatal("expect ErrTooFewArgsToCall error, got", err)
+	}
+}
+
+func TestFuncsAdd(t *testing.T) {
+	funcs := Funcs{}
+	if err := funcs.Add("f", func() string { return "" }); err == nil {
+		t.Fatal("expect an error")
+	} else if _, ok := err.(ErrReturnValNotFloat64); !ok {
+		t.Fatal("expect ErrReturnValNotFloat64 error, got", err)
+	}
+	if _, ok := funcs["f"]; ok {
+		t.Fatal("expect illegal function not added")
+	}
+
+	if err := funcs.Add("hypot", math.Hypot); err != nil {
+		t.Fatal("expect no error, got", err)
+	}
+	if _, ok := funcs["hypot"].(Func2); !ok {
+		t.Fatal("expect function converted to Func2 on Add, got", funcs["hypot"])
+	}
+	funcs.Add("ldexp", math.Ldexp)
+	if _, ok := funcs["ldexp"].(reflected); !ok {
+		t.Fatal("expect function converted on Add, got", funcs["ldexp"])
+	}
+	funcs.Add("sum", func(xs ...float64) float64 { return xs[0] + xs[1] })
+	if _, ok := funcs["sum"].(FuncN); !ok {
+		t.Fatal("expect function converted to FuncN on Add, got", funcs["sum"])
+	}
+
+	expr, _ := Parse("hypot(sum(1, 2), 4)")
+	if val, err := expr.Val(Env{Funcs: funcs}); err != nil {
+		t.Fatal(err)
+	} else if val != 5 {
+		t.Fatal("expect 5, got", val)
+	}
+}
+
+func TestCallIllegalFunc(t *testing.T) {
+	env := Env{Funcs: Funcs{"f": nil}}
+	expr, _ := Parse("f()")
+	if _, err := expr.Val(env); err == nil {
+		t.Fatal("expect ErrNotFunction error")
+	} else if _, ok := err.(ErrNotFunction); !ok {
+		t.Fatal("expect ErrNotFunction error, got", err)
+	}
+}
+
+func TestFuncReturnError(t *testing.T) {
+	errNegative := errors.New("sqrt of negative number")
+	env := Env{Funcs: Funcs{
+		"sqrt": func(x float64) (float64, error) {
+			if x < 0 {
+				return 0, errNegative
+			}
+			return math.Sqrt(x), nil
+		},
+	}}
+	if err := env.Funcs.Check(); err != nil {
+		t.Fatal("expect no error, got", err)
+	}
+
+	expr, _ := Parse("sqrt(4)")
+	if val, err := expr.Val(env); err != nil {
+		t.Fatal(err)
+	} else if val != 2 {
+		t.Fatal("expect 2, got", val)
+	}
+
+	expr, _ = Parse("1 + sqrt(-4)")
+	_, err := expr.Val(env)
+	var ferr ErrFuncFailed
+	if !errors.As(err, &ferr) {
+		t.Fatal("expect ErrFuncFailed error, got", err)
+	} else if ferr.Name != "sqrt" || ferr.Position != (Position{1, 5}) {
+		t.Fatal("function name or position not correct:", ferr.Name, ferr.Position)
+	} else if !errors.Is(err, errNegative) {
+		t.Fatal("expect wrapped error")
+	}
+}
+
+func TestFuncPanic(t *testing.T) {
+	env := Env{Funcs: Funcs{
+		"boom": Func1(func(x float64) float64 { panic("boom") }),
+	}}
+
+	expr, _ := Parse("2 * boom(1)")
+	_, err := expr.Val(env)
+	var perr ErrFuncPanicked
+	if !errors.As(err, &perr) {
+		t.Fatal("expect ErrFuncPanicked error, got", err)
+	} else if perr.Name != "boom" || perr.Position != (Position{1, 5}) {
+		t.Fatal("function name or position not correct:", perr.Name, perr.Position)
+	} else if perr.Value != "boom" || len(perr.Stack) == 0 {
+		t.Fatal("expect panic value and stack trace")
+	}
+
+	// an ErrFuncPanicked returned by a function is just an error of the call
+	env.Funcs["nested"] = func() (float64, error) { return expr.Val(env) }
+	outer, _ := Parse("nested()")
+	_, err = outer.Val(env)
+	var ferr ErrFuncFailed
+	if !errors.As(err, &ferr) {
+		t.Fatal("expect ErrFuncFailed error, got", err)
+	} else if ferr.Name != "nested" || ferr.Position != (Position{1, 1}) {
+		t.Fatal("function name or position not correct:", ferr.Name, ferr.Position)
+	} else if !errors.As(err, &perr) || perr.Name != "boom" {
+		t.Fatal("expect wrapped ErrFuncPanicked, got", err)
+	}
+
+	env.KeepPanics = true
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Fatal("expect panic to propagate, got", r)
+		}
+	}()
+	expr.Val(env)
+	t.Fatal("expect panic")
+}
+
+func benchmarkCall(b *testing.B, fun interface{}) {
+	env := Env{Funcs: Funcs{"f": fun}}
+	expr, _ := Parse("f(1, 2)")
+	for i := 0; i < b.N; i++ {
+		expr.Val(env)
+	}
+}
+
+func BenchmarkCallReflect(b *testing.B) { benchmarkCall(b, math.Ldexp) }
+func BenchmarkCallTyped(b *testing.B)   { benchmarkCall(b, Func2(math.Hypot)) }
+
+func TestNumericParams(t *testing.T) {
+	type Cents int64
+	env := Env{Funcs: Funcs{
+		"dollars": func(c Cents) float32 { return float32(c) / 100 },
+		"shift":   func(x uint8, n int) uint64 { return uint64(x) << uint(n) },
+		"not":     func(b bool) bool { return !b },
+		"count":   func(xs ...int) int { return len(xs) },
+	}}
+	if err := env.Funcs.Check(); err != nil {
+		t.Fatal("expect no error, got", err)
+	}
+
+	cases := map[string]float64{
+		"dollars(250)":     2.5,
+		"shift(255, 8)":    65280,
+		"not(0) + not(1)":  1,
+		"count(1, 2, 3)":   3,
+		"dollars(-0b1010)": -0.1,
+	}
+	for src, want := range cases {
+		expr, err := Parse(src)
+		if err != nil {
+			t.Fatal(src, err)
+		}
+		if got, err := expr.Val(env); err != nil {
+			t.Fatal(src, err)
+		} else if math.Abs(got-want) > 1e-6 {
+			t.Fatalf("%s: expect %v, got %v", src, want, got)
+		}
+	}
+
+	for src, n := range map[string]int{
+		"dollars(2.5)":  1,
+		"shift(256, 1)": 1,
+		"shift(-1, 1)":  1,
+		"not(2)":        1,
+		"count(1, 2.5)": 2,
+	} {
+		expr, _ := Parse(src)
+		var cerr ErrArgConversion
+		if _, err := expr.Val(env); !errors.As(err, &cerr) {
+			t.Fatal(src, "expect ErrArgConversion error, got", err)
+		} else if cerr.N != n || cerr.Position.Col != 1 {
+			t.Fatal(src, "argument number or position not correct:", cerr.N, cerr.Position)
+		}
+	}
+}
+
+func TestOverloads(t *testing.T) {
+	env := Env{Funcs: Funcs{}}
+	err := env.Register("area", Overloads{
+		Func1(func(r float64) float64 { return 3 * r * r }),
+		func(w, h int) int { return w * h },
+		FuncN(func(args []float64) float64 { return float64(len(args)) }),
+	}, FuncInfo{Params: []string{"a", "b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for src, expected := range map[string]float64{
+		"area(2)":          12,
+		"area(2, 3)":       6,
+		"area(1, 2, 3, 4)": 4,
+	} {
+		expr, _ := Parse(src)
+		if val, err := expr.Val(env); err != nil {
+			t.Fatal(src, err)
+		} else if val != expected {
+			t.Fatalf("%s: expect %v, got %v", src, expected, val)
+		}
+	}
+
+	if sig := env.Functions()[0].Signature; sig != "area(a) | area(a, b) | area(a...)" {
+		t.Fatal("unexpected signature", sig)
+	}
+
+	env.Funcs["area"] = Overloads{Func1(math.Abs), Func3(clamp)}
+	expr, _ := Parse("area(1, 2)")
+	var oerr ErrNoOverload
+	if _, err := expr.Val(env); !errors.As(err, &oerr) {
+		t.Fatal("expect ErrNoOverload error, got", err)
+	} else if err.Error() != `no overload of function "area" takes 2 arguments, candidates are area(x1), area(x1, x2, x3)` {
+		t.Fatal("unexpected message", err)
+	}
+
+	if err := (Funcs{}).Add("bad", Overloads{math.Abs, "abs"}); err == nil {
+		t.Fatal("expect illegal overloads to be rejected")
+	}
+	if err := (Funcs{}).Add("none", Overloads{}); err == nil {
+		t.Fatal("expect empty overloads to be rejected")
+	}
+}
+
+func TestRawFuncsOfOneType(t *testing.T) {
+	// both closures share the cached shape of their type, but not the function
+	add := func(n float64) func(float64) float64 {
+		return func(x float64) float64 { return x + n }
+	}
+	env := Env{Funcs: Funcs{"inc": add(1), "dec": add(-1)}}
+	expr, _ := Parse("inc(10) * dec(10)")
+	for i := 0; i < 2; i++ {
+		if got, err := expr.Val(env); err != nil || got != 99 {
+			t.Fatal("expect 99, got", got, err)
+		}
+	}
+}
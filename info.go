@@ -0,0 +1,182 @@
+package sec
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type (
+	// FuncInfo documents a function for help texts and editors.
+	FuncInfo struct {
+		Doc string `json:"doc,omitempty"`
+		// Params names the parameters, for example
+		// []string{"rate", "nper", "pv", "[fv]", "[type]"}.
+		Params   []string `json:"params,omitempty"`
+		Category string   `json:"category,omitempty"`
+		Examples []string `json:"examples,omitempty"`
+		// Pure marks a function whose result depends on its arguments
+		// only, Optimize evaluates calls of it with constant arguments.
+		Pure bool `json:"pure,omitempty"`
+	}
+
+	// FuncDesc describes a function in an Env, it is what Env.Functions
+	// returns and can be marshaled to JSON directly.
+	FuncDesc struct {
+		Name string `json:"name"`
+		// Signature is like "clamp(x, lo, hi)", parameters without a
+		// name in FuncInfo.Params are named x1, x2 and so on. The last
+		// parameter of a variadic function is followed by "...", and
+		// names the rest of the arguments if Params names exactly the
+		// required ones. It is empty for illegal functions.
+		Signature string `json:"signature"`
+		FuncInfo
+	}
+)
+
+// Register checks fun and adds it to e.Funcs as name, and info to e.Info.
+func (e *Env) Register(name string, fun interface{}, info FuncInfo) error {
+	if e.Funcs == nil {
+		e.Funcs = make(Funcs)
+	}
+	if err := e.Funcs.Add(name, fun); err != nil {
+		return err
+	}
+	if e.Info == nil {
+		e.Info = make(map[string]FuncInfo)
+	}
+	e.Info[name] = info
+	return nil
+}
+
+// doc makes the FuncInfo of a pure built-in function, whose parameters are
+// separated by commas in params.
+func doc(params, text string, examples ...string) FuncInfo {
+	info := FuncInfo{Doc: text, Examples: examples, Pure: true}
+	if params != "" {
+		info.Params = strings.Split(params, ", ")
+	}
+	return info
+}
+
+// envDoc is doc for a built-in function whose result depends on the Env, like
+// on its AngleMode or Rand, so it is not pure.
+func envDoc(params, text string, examples ...string) FuncInfo {
+	info := doc(params, text, examples...)
+	info.Pure = false
+	return info
+}
+
+// load registers consts and funcs of the given category in e, documented by
+// info.
+func (e *Env) load(category string, consts Vars, funcs Funcs, info map[string]FuncInfo) {
+	if e.Consts == nil {
+		e.Consts = make(Vars, len(consts))
+	}
+	for name, val := range consts {
+		e.Consts[name] = val
+	}
+	for name, fun := range funcs {
+		fi := info[name]
+		fi.Category = category
+		if err := e.Register(name, fun, fi); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Functions describes every function in e.Funcs, in the namespaces of e
+// under their qualified names, and in the ancestors of e, sorted by name.
+// Functions of resolvers are not included.
+func (e Env) Functions() []FuncDesc {
+	descs := []FuncDesc{}
+	seen := make(map[string]bool)
+	add := func(desc FuncDesc) {
+		if !seen[desc.Name] {
+			seen[desc.Name] = true
+			descs = append(descs, desc)
+		}
+	}
+
+	// visit every scope in the order names are looked up
+	e.find(func(s *Env) bool {
+		for name, fun := range s.Funcs {
+			desc := FuncDesc{Name: name, FuncInfo: s.Info[name]}
+			if f, err := toFunction(name, fun); err == nil {
+				desc.Signature = signature(name, f, desc.Params)
+			}
+			add(desc)
+		}
+		for prefix, ns := range s.namespaces {
+			for _, desc := range ns.Functions() {
+				desc.Name = prefix + "." + desc.Name
+				if desc.Signature != "" {
+					desc.Signature = prefix + "." + desc.Signature
+				}
+				add(desc)
+			}
+		}
+		return false
+	})
+
+	sort.Slice(descs, func(i, j int) bool { return descs[i].Name < descs[j].Name })
+	return descs
+}
+
+// funcInfo returns the info of the function name resolves to in e, like
+// LookupFunc does.
+func (e Env) funcInfo(name string) (info FuncInfo) {
+	e.find(func(s *Env) bool {
+		if _, ok := s.Funcs[name]; ok {
+			info = s.Info[name]
+			return true
+		}
+		if s.FuncResolver != nil {
+			if _, ok := s.FuncResolver.LookupFunc(name); ok {
+				return true
+			}
+		}
+		if ns, rest := s.namespace(name); ns != nil {
+			if _, ok := ns.LookupFunc(rest); ok {
+				info = ns.funcInfo(rest)
+				return true
+			}
+		}
+		return false
+	})
+	return
+}
+
+func signature(name string, f function, params []string) string {
+	if o, ok := f.(overloaded); ok {
+		sigs := make([]string, len(o))
+		for i, f := range o {
+			sigs[i] = signature(name, f, params)
+		}
+		return strings.Join(sigs, " | ")
+	}
+
+	min, max := f.arity()
+	n := max
+	if max < 0 {
+		n = min + 1
+		if min > 0 && len(params) == min {
+			n = min
+		}
+	}
+
+	names := make([]string, n)
+	for i := range names {
+		if i < len(params) {
+			names[i] = params[i]
+		} else if i >= min {
+			names[i] = fmt.Sprintf("[x%d]", i+1)
+		} else {
+			names[i] = fmt.Sprintf("x%d", i+1)
+		}
+	}
+	if max < 0 {
+		names[n-1] += "..."
+	}
+	return name + "(" + strings.Join(names, ", ") + ")"
+}
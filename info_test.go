@@ -0,0 +1,96 @@
+package sec
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestRegister(t *testing.T) {
+	var env Env
+	err := env.Register("clamp", clamp, FuncInfo{
+		Doc:      "clamp limits x to the range [lo, hi].",
+		Params:   []string{"x", "lo", "hi"},
+		Category: "math",
+		Examples: []string{"clamp(15, 0, 10)"},
+	})
+	if err != nil {
+		t.Fatal("expect no error, got", err)
+	}
+	if err := env.Register("bad", "not a function", FuncInfo{}); err == nil {
+		t.Fatal("expect ErrNotFunction error")
+	} else if _, ok := err.(ErrNotFunction); !ok {
+		t.Fatal("expect ErrNotFunction error, got", err)
+	}
+	env.Funcs["hypot"] = math.Hypot
+	env.Funcs["max"] = variadic(1, maximum)
+	env.Funcs["zero"] = Func0(func() float64 { return 0 })
+
+	descs := env.Functions()
+	want := []struct{ name, signature, doc string }{
+		{"clamp", "clamp(x, lo, hi)", "clamp limits x to the range [lo, hi]."},
+		{"hypot", "hypot(x1, x2)", ""},
+		{"max", "max(x1, [x2]...)", ""},
+		{"zero", "zero()", ""},
+	}
+	if len(descs) != len(want) {
+		t.Fatalf("expect %d functions, got %d", len(want), len(descs))
+	}
+	for i, w := range want {
+		if d := descs[i]; d.Name != w.name || d.Signature != w.signature || d.Doc != w.doc {
+			t.Fatalf("expect %v, got %v", w, d)
+		}
+	}
+
+	data, err := json.Marshal(descs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	const wantJSON = `{"name":"clamp","signature":"clamp(x, lo, hi)",` +
+		`"doc":"clamp limits x to the range [lo, hi].","params":["x","lo","hi"],` +
+		`"category":"math","examples":["clamp(15, 0, 10)"]}`
+	if string(data) != wantJSON {
+		t.Fatal("unexpected JSON:", string(data))
+	}
+}
+
+func TestLoadCategory(t *testing.T) {
+	var env Env
+	env.LoadMath()
+	env.LoadFinance()
+	for _, d := range env.Functions() {
+		switch d.Name {
+		case "sqrt":
+			if d.Category != "math" {
+				t.Fatal("expect category math, got", d.Category)
+			}
+		case "pmt":
+			if d.Category != "finance" || d.Signature != "pmt(rate, nper, pv, [fv], [type])" {
+				t.Fatal("unexpected description:", d)
+			}
+		}
+	}
+}
+
+func TestBuiltinInfo(t *testing.T) {
+	var env Env
+	env.LoadMath()
+	env.LoadStats()
+	env.LoadFinance()
+	env.LoadInteger()
+	env.LoadRand()
+	for _, d := range env.Functions() {
+		if d.Doc == "" || len(d.Examples) == 0 {
+			t.Fatal("expect doc and examples for", d.Name)
+		}
+		for _, src := range d.Examples {
+			expr, err := Parse(src)
+			if err != nil {
+				t.Fatal(src, err)
+			}
+			if _, err := expr.Val(env); err != nil {
+				t.Fatal(src, err)
+			}
+		}
+	}
+}
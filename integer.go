@@ -0,0 +1,217 @@
+package sec
+
+import (
+	"math"
+	"math/big"
+)
+
+// IntegerFuncs is a set of combinatorics and number theory functions. They
+// compute exactly with math/big. Their arguments must be integers, and when
+// an exact result does not fit in a float64 they fail with ErrPrecisionLoss
+// instead of rounding. isPrime returns 1 for primes and 0 otherwise, modinv
+// fails with ErrArgOutOfRange when the inverse does not exist.
+var IntegerFuncs = Funcs{
+	"factorial": pure(1, 1, factorial),
+	"nCr":       pure(2, 2, nCr),
+	"nPr":       pure(2, 2, nPr),
+	"fib":       pure(1, 1, fib),
+	"isPrime":   pure(1, 1, isPrime),
+	"nextPrime": pure(1, 1, nextPrime),
+	"modpow":    pure(3, 3, modpow),
+	"modinv":    pure(2, 2, modinv),
+	"digitSum":  pure(1, 1, digitSum),
+}
+
+// integerInfo documents IntegerFuncs.
+var integerInfo = map[string]FuncInfo{
+	"factorial": doc("n", "factorial returns n!.", "factorial(10)"),
+	"nCr":       doc("n, k", "nCr returns the number of ways to choose k of n items, regardless of order.", "nCr(5, 2)"),
+	"nPr":       doc("n, k", "nPr returns the number of ways to choose k of n items in order.", "nPr(5, 2)"),
+	"fib":       doc("n", "fib returns the n-th Fibonacci number.", "fib(10)"),
+	"isPrime":   doc("n", "isPrime returns 1 if n is prime, 0 otherwise.", "isPrime(97)"),
+	"nextPrime": doc("n", "nextPrime returns the least prime greater than n.", "nextPrime(100)"),
+	"modpow":    doc("b, e, m", "modpow returns b**e mod m.", "modpow(2, 10, 1000)"),
+	"modinv":    doc("a, m", "modinv returns the x for which a*x mod m is 1.", "modinv(3, 11)"),
+	"digitSum":  doc("n", "digitSum returns the sum of the decimal digits of n.", "digitSum(1234)"),
+}
+
+// LoadInteger adds IntegerFuncs to e. Existing names are overwritten.
+func (e *Env) LoadInteger() { e.load("integer", nil, IntegerFuncs, integerInfo) }
+
+// Results beyond these bounds are known to overflow a float64, they save us
+// from computing huge numbers only to throw them away.
+const (
+	maxFactorial = 170  // 171! > math.MaxFloat64
+	maxFib       = 1476 // fib(1477) > math.MaxFloat64
+	maxChoose    = 1030 // nCr(2k, k) > math.MaxFloat64 for k > maxChoose
+)
+
+// bigArgs converts the arguments of a function to big integers. Arguments
+// whose index is in natural must not be negative.
+func bigArgs(args []float64, natural ...int) ([]*big.Int, error) {
+	ints := make([]*big.Int, len(args))
+	for i, arg := range args {
+		if !isInteger(arg) {
+			return nil, ErrArgNotInteger{i + 1}
+		}
+		ints[i], _ = big.NewFloat(arg).Int(nil)
+	}
+	for _, i := range natural {
+		if ints[i].Sign() < 0 {
+			return nil, ErrArgOutOfRange{i + 1}
+		}
+	}
+	return ints, nil
+}
+
+// exact converts z to a float64, failing if it can not be done exactly.
+func exact(z *big.Int) (float64, error) {
+	f, acc := new(big.Float).SetInt(z).Float64()
+	if acc != big.Exact || math.IsInf(f, 0) {
+		return 0, ErrPrecisionLoss{}
+	}
+	return f, nil
+}
+
+func factorial(args []float64) (float64, error) {
+	n, err := bigArgs(args, 0)
+	if err != nil {
+		return 0, err
+	}
+	if n[0].Cmp(big.NewInt(maxFactorial)) > 0 {
+		return 0, ErrPrecisionLoss{}
+	}
+	return exact(new(big.Int).MulRange(1, n[0].Int64()))
+}
+
+func nCr(args []float64) (float64, error) {
+	ints, err := bigArgs(args, 0, 1)
+	if err != nil {
+		return 0, err
+	}
+	n, k := ints[0], ints[1]
+	if k.Cmp(n) > 0 {
+		return 0, nil
+	}
+	if r := new(big.Int).Sub(n, k); r.Cmp(k) < 0 {
+		k = r // nCr(n, k) == nCr(n, n-k)
+	}
+	if k.Cmp(big.NewInt(maxChoose)) > 0 {
+		return 0, ErrPrecisionLoss{}
+	}
+	z := big.NewInt(1)
+	for i := int64(1); i <= k.Int64(); i++ {
+		// z*(n-k+i) is always divisible by i here
+		z.Mul(z, new(big.Int).Add(new(big.Int).Sub(n, k), big.NewInt(i)))
+		z.Quo(z, big.NewInt(i))
+	}
+	return exact(z)
+}
+
+func nPr(args []float64) (float64, error) {
+	ints, err := bigArgs(args, 0, 1)
+	if err != nil {
+		return 0, err
+	}
+	n, k := ints[0], ints[1]
+	if k.Cmp(n) > 0 {
+		return 0, nil
+	}
+	if k.Cmp(big.NewInt(maxFactorial)) > 0 {
+		return 0, ErrPrecisionLoss{}
+	}
+	z := big.NewInt(1)
+	for i := new(big.Int).Sub(n, k); i.Cmp(n) < 0; {
+		i.Add(i, big.NewInt(1))
+		z.Mul(z, i)
+	}
+	return exact(z)
+}
+
+func fib(args []float64) (float64, error) {
+	n, err := bigArgs(args, 0)
+	if err != nil {
+		return 0, err
+	}
+	if n[0].Cmp(big.NewInt(maxFib)) > 0 {
+		return 0, ErrPrecisionLoss{}
+	}
+	a, b := big.NewInt(0), big.NewInt(1)
+	for i := n[0].Int64(); i > 0; i-- {
+		a.Add(a, b)
+		a, b = b, a
+	}
+	return exact(a)
+}
+
+func isPrime(args []float64) (float64, error) {
+	n, err := bigArgs(args)
+	if err != nil {
+		return 0, err
+	}
+	// ProbablyPrime is exact for values less than 2^64
+	if n[0].Sign() > 0 && n[0].ProbablyPrime(0) {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// nextPrime returns the smallest prime greater than its argument.
+func nextPrime(args []float64) (float64, error) {
+	n, err := bigArgs(args)
+	if err != nil {
+		return 0, err
+	}
+	z := n[0]
+	if z.Sign() < 0 {
+		z.SetInt64(0)
+	}
+	for z.Add(z, big.NewInt(1)); !z.ProbablyPrime(0); {
+		z.Add(z, big.NewInt(1))
+	}
+	return exact(z)
+}
+
+// modpow returns b**e mod m.
+func modpow(args []float64) (float64, error) {
+	ints, err := bigArgs(args, 1)
+	if err != nil {
+		return 0, err
+	}
+	b, e, m := ints[0], ints[1], ints[2]
+	if m.Sign() <= 0 {
+		return 0, ErrArgOutOfRange{3}
+	}
+	z := new(big.Int).Exp(b, e, m)
+	return exact(z.Mod(z, m))
+}
+
+// modinv returns the modular multiplicative inverse of a modulo m.
+func modinv(args []float64) (float64, error) {
+	ints, err := bigArgs(args)
+	if err != nil {
+		return 0, err
+	}
+	a, m := ints[0], ints[1]
+	if m.Sign() <= 0 {
+		return 0, ErrArgOutOfRange{2}
+	}
+	a = new(big.Int).Mod(a, m)
+	if new(big.Int).GCD(nil, nil, a, m).Cmp(big.NewInt(1)) != 0 {
+		return 0, ErrArgOutOfRange{1}
+	}
+	return exact(new(big.Int).ModInverse(a, m))
+}
+
+// digitSum returns the sum of the decimal digits of its argument.
+func digitSum(args []float64) (float64, error) {
+	n, err := bigArgs(args)
+	if err != nil {
+		return 0, err
+	}
+	var sum float64
+	for _, d := range new(big.Int).Abs(n[0]).String() {
+		sum += float64(d - '0')
+	}
+	return sum, nil
+}
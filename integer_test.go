@@ -0,0 +1,86 @@
+package sec
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIntegerFuncsCheck(t *testing.T) {
+	if err := IntegerFuncs.Check(); err != nil {
+		t.Fatal("expect no error, got", err)
+	}
+}
+
+func TestLoadInteger(t *testing.T) {
+	var env Env
+	env.LoadInteger()
+
+	cases := map[string]float64{
+		"factorial(0)":       1,
+		"factorial(10)":      3628800,
+		"nCr(5, 2)":          10,
+		"nCr(60, 30)":        118264581564861424,
+		"nCr(2, 3)":          0,
+		"nPr(5, 2)":          20,
+		"fib(0) + fib(1)":    1,
+		"fib(78)":            8944394323791464,
+		"isPrime(97)":        1,
+		"isPrime(1)":         0,
+		"nextPrime(13)":      17,
+		"nextPrime(-5)":      2,
+		"modpow(4, 13, 497)": 445,
+		"modpow(-2, 3, 5)":   2,
+		"modinv(3, 11)":      4,
+		"digitSum(9875)":     29,
+		"digitSum(-0b111)":   7,
+		"factorial(22)":      1124000727777607680000,
+	}
+
+	expectVals(t, env, 0, cases)
+}
+
+func TestIntegerErrors(t *testing.T) {
+	var env Env
+	env.LoadInteger()
+
+	cases := map[string]error{
+		"factorial(171)":    ErrPrecisionLoss{},
+		"factorial(27)":     ErrPrecisionLoss{},
+		"fib(79)":           ErrPrecisionLoss{},
+		"nCr(100, 50)":      ErrPrecisionLoss{},
+		"factorial(1.5)":    ErrArgNotInteger{1},
+		"nCr(5, -1)":        ErrArgOutOfRange{2},
+		"modpow(2, 3, 0)":   ErrArgOutOfRange{3},
+		"modinv(2, 4)":      ErrArgOutOfRange{1},
+		"modpow(2, 0.5, 3)": ErrArgNotInteger{2},
+	}
+
+	var psr Parser
+	for src, want := range cases {
+		expr, err := psr.Parse(src)
+		if err != nil {
+			t.Fatal(src, err)
+		}
+		if _, err := expr.Val(env); !errors.Is(err, want) {
+			t.Fatalf("%s: expect %v, got %v", src, want, err)
+		}
+	}
+}
+
+func TestIntegerErrorNames(t *testing.T) {
+	var env Env
+	env.Namespace("int").LoadInteger()
+
+	expr, _ := Parse("1 + int.factorial(171)")
+	_, err := expr.Val(env)
+	var ferr ErrFuncFailed
+	if !errors.As(err, &ferr) {
+		t.Fatal("expect ErrFuncFailed error, got", err)
+	} else if ferr.Name != "int.factorial" || ferr.Position != (Position{1, 5}) {
+		t.Fatal("function name or position not correct:", ferr.Name, ferr.Position)
+	}
+	const want = `function "int.factorial" failed: result does not fit in a float64`
+	if err.Error() != want {
+		t.Fatalf("expect %q, got %q", want, err.Error())
+	}
+}
@@ -0,0 +1,233 @@
+package sec
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// LoadOptions controls how the Read methods of Vars name variables.
+type LoadOptions struct {
+	// Prefix makes only the keys starting with it be loaded, with Prefix
+	// removed, APP_ for example.
+	Prefix string
+	// Normalize turns keys into identifiers, by replacing every character
+	// other than letters, digits and '_' with '_', and prepending '_' to
+	// keys starting with a digit. Dots are kept, each part of a dotted key
+	// is normalized alone.
+	Normalize bool
+}
+
+var (
+	errNotNumber = errors.New("not a number or a bool")
+	errNoEqual   = errors.New("missing '='")
+)
+
+// name returns the name of the variable loaded from key, or false if key is
+// to be skipped.
+func (o LoadOptions) name(key string) (string, bool) {
+	if !strings.HasPrefix(key, o.Prefix) {
+		return "", false
+	}
+	key = strings.TrimPrefix(key, o.Prefix)
+	if !o.Normalize {
+		return key, true
+	}
+
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		var b strings.Builder
+		for j, ch := range part {
+			if j == 0 && isNumber(ch) {
+				b.WriteByte('_')
+			}
+			if isAlpha(ch) || isNumber(ch) || ch == '_' {
+				b.WriteRune(ch)
+			} else {
+				b.WriteByte('_')
+			}
+		}
+		if b.Len() == 0 {
+			b.WriteByte('_')
+		}
+		parts[i] = b.String()
+	}
+	return strings.Join(parts, "."), true
+}
+
+// parseValue parses a number as accepted by strconv.ParseFloat, or true or
+// false as 1 or 0.
+func parseValue(s string) (float64, error) {
+	switch s {
+	case "true":
+		return 1, nil
+	case "false":
+		return 0, nil
+	}
+	val, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, errNotNumber
+	}
+	return val, nil
+}
+
+// ReadJSON adds the members of a JSON object read from r to v. Members must
+// be numbers, bools or objects, the members of nested objects are named
+// like outer.inner. Nothing is added if it fails.
+func (v Vars) ReadJSON(r io.Reader, opts LoadOptions) error {
+	read := Vars{}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	src := bytes.NewReader(data)
+	dec := json.NewDecoder(src)
+	dec.UseNumber()
+
+	// line returns the line the decoder stopped at.
+	line := func() int {
+		buffered, _ := io.Copy(ioutil.Discard, dec.Buffered())
+		offset := len(data) - src.Len() - int(buffered)
+		return 1 + bytes.Count(data[:offset], []byte{'\n'})
+	}
+	fail := func(key string, err error) error {
+		if serr, ok := err.(*json.SyntaxError); ok {
+			return ErrLoad{1 + bytes.Count(data[:serr.Offset], []byte{'\n'}), key, err}
+		}
+		return ErrLoad{line(), key, err}
+	}
+
+	var readObject func(prefix string) error
+	readObject = func(prefix string) error {
+		for dec.More() {
+			tk, err := dec.Token()
+			if err != nil {
+				return fail(prefix, err)
+			}
+			key := prefix + tk.(string)
+			if tk, err = dec.Token(); err != nil {
+				return fail(key, err)
+			}
+
+			var val float64
+			switch tk := tk.(type) {
+			case json.Delim:
+				if tk != '{' {
+					return fail(key, errNotNumber)
+				}
+				if err = readObject(key + "."); err != nil {
+					return err
+				}
+				if _, err = dec.Token(); err != nil {
+					return fail(key, err)
+				}
+				continue
+			case json.Number:
+				if val, err = tk.Float64(); err != nil {
+					return fail(key, err)
+				}
+			case bool:
+				if tk {
+					val = 1
+				}
+			default:
+				return fail(key, errNotNumber)
+			}
+			if name, ok := opts.name(key); ok {
+				read[name] = val
+			}
+		}
+		return nil
+	}
+
+	if tk, err := dec.Token(); err != nil {
+		return fail("", err)
+	} else if tk != json.Delim('{') {
+		return fail("", errors.New("not a JSON object"))
+	}
+	if err = readObject(""); err != nil {
+		return err
+	}
+	if _, err = dec.Token(); err != nil {
+		return fail("", err)
+	}
+	v.merge(read)
+	return nil
+}
+
+// ReadDotEnv adds the variables in a .env file read from r to v. Each line
+// of the file is empty, a comment starting with '#', or KEY=VALUE, where
+// KEY may be preceded by export, and VALUE may be quoted or else followed by
+// a comment after a space. Nothing is added if it fails.
+func (v Vars) ReadDotEnv(r io.Reader, opts LoadOptions) error {
+	read := Vars{}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		i := strings.IndexByte(line, '=')
+		if i < 0 {
+			return ErrLoad{n, "", errNoEqual}
+		}
+		key := strings.TrimSpace(strings.TrimPrefix(line[:i], "export "))
+		val := strings.TrimSpace(line[i+1:])
+		if len(val) >= 2 && (val[0] == '"' || val[0] == '\'') && val[len(val)-1] == val[0] {
+			val = val[1 : len(val)-1]
+		} else if j := strings.IndexAny(val, " \t"); j >= 0 && strings.TrimLeft(val[j:], " \t")[0] == '#' {
+			val = val[:j]
+		}
+
+		name, ok := opts.name(key)
+		if !ok {
+			continue
+		}
+		x, err := parseValue(val)
+		if err != nil {
+			return ErrLoad{n, key, err}
+		}
+		read[name] = x
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	v.merge(read)
+	return nil
+}
+
+// ReadEnviron adds the variables in environ, in the form of os.Environ, to
+// v. As every variable must hold a number, a Prefix is usually needed.
+// Nothing is added if it fails.
+func (v Vars) ReadEnviron(environ []string, opts LoadOptions) error {
+	read := Vars{}
+	for _, kv := range environ {
+		i := strings.IndexByte(kv, '=')
+		if i < 0 {
+			continue
+		}
+		name, ok := opts.name(kv[:i])
+		if !ok {
+			continue
+		}
+		x, err := parseValue(kv[i+1:])
+		if err != nil {
+			return ErrLoad{Key: kv[:i], Err: err}
+		}
+		read[name] = x
+	}
+	v.merge(read)
+	return nil
+}
+
+// merge adds the variables in read to v.
+func (v Vars) merge(read Vars) {
+	for name, x := range read {
+		v[name] = x
+	}
+}
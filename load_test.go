@@ -0,0 +1,108 @@
+package sec
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadJSON(t *testing.T) {
+	src := `{
+	"rate": 0.05,
+	"tax-rate": 1.5e-1,
+	"enabled": true,
+	"geo": {"2d": {"x": 1}, "y": -2}
+}`
+	vars := Vars{"old": 1}
+	if err := vars.ReadJSON(strings.NewReader(src), LoadOptions{Normalize: true}); err != nil {
+		t.Fatal(err)
+	}
+	expected := Vars{"old": 1, "rate": 0.05, "tax_rate": 0.15, "enabled": 1, "geo._2d.x": 1, "geo.y": -2}
+	if !reflect.DeepEqual(vars, expected) {
+		t.Fatal("expect", expected, "got", vars)
+	}
+
+	vars = Vars{}
+	if err := vars.ReadJSON(strings.NewReader(src), LoadOptions{Prefix: "geo."}); err != nil {
+		t.Fatal(err)
+	}
+	if expected = (Vars{"2d.x": 1, "y": -2}); !reflect.DeepEqual(vars, expected) {
+		t.Fatal("expect", expected, "got", vars)
+	}
+
+	for src, line := range map[string]int{
+		"{\n\"a\": 1,\n\"b\": \"x\"\n}": 3,
+		"{\n\"a\": [1]}":                2,
+		"{\n\"a\": null}":               2,
+		"{\n\n\"a\": 1,,}":              3,
+		"[1]":                           1,
+	} {
+		var lerr ErrLoad
+		vars := Vars{}
+		if err := vars.ReadJSON(strings.NewReader(src), LoadOptions{}); !errors.As(err, &lerr) {
+			t.Fatalf("%q: expect ErrLoad error, got %v", src, err)
+		} else if lerr.Line != line {
+			t.Fatalf("%q: expect an error at line %d, got %v", src, line, err)
+		} else if len(vars) != 0 {
+			t.Fatalf("%q: expect nothing added on failure, got %v", src, vars)
+		}
+	}
+}
+
+func TestReadDotEnv(t *testing.T) {
+	src := `# limits
+APP_MAX_RETRIES=3
+export APP_RATE = "0.25"
+APP_DEBUG='false'
+APP_LIMIT=10 # per minute
+APP_MIN=1	# tab
+
+OTHER=x
+`
+	vars := Vars{}
+	if err := vars.ReadDotEnv(strings.NewReader(src), LoadOptions{Prefix: "APP_"}); err != nil {
+		t.Fatal(err)
+	}
+	expected := Vars{"MAX_RETRIES": 3, "RATE": 0.25, "DEBUG": 0, "LIMIT": 10, "MIN": 1}
+	if !reflect.DeepEqual(vars, expected) {
+		t.Fatal("expect", expected, "got", vars)
+	}
+
+	var lerr ErrLoad
+	if err := vars.ReadDotEnv(strings.NewReader(src), LoadOptions{}); !errors.As(err, &lerr) {
+		t.Fatal("expect ErrLoad error, got", err)
+	} else if lerr.Line != 8 || lerr.Key != "OTHER" {
+		t.Fatal("unexpected", err)
+	} else if err.Error() != `line 8: "OTHER": not a number or a bool` {
+		t.Fatal("unexpected message", err)
+	}
+	if !reflect.DeepEqual(vars, expected) {
+		t.Fatal("expect nothing added on failure, got", vars)
+	}
+
+	if err := vars.ReadDotEnv(strings.NewReader("a=1\nb\n"), LoadOptions{}); !errors.As(err, &lerr) {
+		t.Fatal("expect ErrLoad error, got", err)
+	} else if lerr.Line != 2 {
+		t.Fatal("unexpected", err)
+	}
+}
+
+func TestReadEnviron(t *testing.T) {
+	environ := []string{"PATH=/bin", "CALC_LIMIT=100", "CALC_9LIVES=9"}
+	vars := Vars{}
+	if err := vars.ReadEnviron(environ, LoadOptions{Prefix: "CALC_", Normalize: true}); err != nil {
+		t.Fatal(err)
+	}
+	expected := Vars{"LIMIT": 100, "_9LIVES": 9}
+	if !reflect.DeepEqual(vars, expected) {
+		t.Fatal("expect", expected, "got", vars)
+	}
+
+	var lerr ErrLoad
+	if err := vars.ReadEnviron(environ, LoadOptions{}); !errors.As(err, &lerr) {
+		t.Fatal("expect ErrLoad error, got", err)
+	} else if lerr.Key != "PATH" {
+		t.Fatal("unexpected", err)
+	}
+}
@@ -0,0 +1,179 @@
+package sec
+
+import (
+	"math"
+)
+
+var (
+	// MathFuncs is a set of common mathematical functions, most of them are
+	// thin wrappers around the math package. Angles taken and returned by
+	// the trigonometric functions are in the Env's AngleMode.
+	MathFuncs = Funcs{
+		"sqrt":  Func1(math.Sqrt),
+		"abs":   Func1(math.Abs),
+		"floor": Func1(math.Floor),
+		"ceil":  Func1(math.Ceil),
+		"round": Overloads{Func1(math.Round), Func2(round)},
+		"trunc": Func1(math.Trunc),
+		"min":   variadic(1, minimum),
+		"max":   variadic(1, maximum),
+		"clamp": Func3(clamp),
+		"exp":   Func1(math.Exp),
+		"log":   Func1(math.Log),
+		"log2":  Func1(math.Log2),
+		"log10": Func1(math.Log10),
+		"sin":   trig(math.Sin),
+		"cos":   trig(math.Cos),
+		"tan":   trig(math.Tan),
+		"asin":  arc(math.Asin),
+		"acos":  arc(math.Acos),
+		"atan":  arc(math.Atan),
+		"atan2": builtin{2, 2, atan2},
+		"sinh":  Func1(math.Sinh),
+		"cosh":  Func1(math.Cosh),
+		"tanh":  Func1(math.Tanh),
+		"asinh": Func1(math.Asinh),
+		"acosh": Func1(math.Acosh),
+		"atanh": Func1(math.Atanh),
+		"hypot": Func2(math.Hypot),
+		"sign":  Func1(sign),
+		"gcd":   variadic(1, gcd),
+		"lcm":   variadic(1, lcm),
+	}
+
+	// MathConsts holds the well-known mathematical constants.
+	MathConsts = Vars{
+		"pi":  math.Pi,
+		"e":   math.E,
+		"phi": math.Phi,
+		"inf": math.Inf(1),
+		"nan": math.NaN(),
+	}
+)
+
+// mathInfo documents MathFuncs.
+var mathInfo = map[string]FuncInfo{
+	"sqrt":  doc("x", "sqrt returns the square root of x.", "sqrt(2)"),
+	"abs":   doc("x", "abs returns the absolute value of x.", "abs(-3)"),
+	"floor": doc("x", "floor returns the greatest integer not greater than x.", "floor(2.7)"),
+	"ceil":  doc("x", "ceil returns the least integer not less than x.", "ceil(2.1)"),
+	"round": doc("x, n", "round rounds x half away from zero to n decimal places, 0 if n is omitted. n may be negative.", "round(2.5)", "round(pi, 2)"),
+	"trunc": doc("x", "trunc returns the integer part of x.", "trunc(-2.7)"),
+	"min":   doc("xs", "min returns the least of its arguments.", "min(3, 1, 2)"),
+	"max":   doc("xs", "max returns the greatest of its arguments.", "max(3, 1, 2)"),
+	"clamp": doc("x, lo, hi", "clamp limits x to the range [lo, hi].", "clamp(15, 0, 10)"),
+	"exp":   doc("x", "exp returns e**x.", "exp(1)"),
+	"log":   doc("x", "log returns the natural logarithm of x.", "log(e)"),
+	"log2":  doc("x", "log2 returns the binary logarithm of x.", "log2(8)"),
+	"log10": doc("x", "log10 returns the decimal logarithm of x.", "log10(1000)"),
+	"sin":   envDoc("x", "sin returns the sine of the angle x.", "sin(30deg)"),
+	"cos":   envDoc("x", "cos returns the cosine of the angle x.", "cos(60deg)"),
+	"tan":   envDoc("x", "tan returns the tangent of the angle x.", "tan(45deg)"),
+	"asin":  envDoc("x", "asin returns the angle whose sine is x.", "asin(0.5)"),
+	"acos":  envDoc("x", "acos returns the angle whose cosine is x.", "acos(0.5)"),
+	"atan":  envDoc("x", "atan returns the angle whose tangent is x.", "atan(1)"),
+	"atan2": envDoc("y, x", "atan2 returns the angle of the point (x, y) from the positive x axis.", "atan2(1, -1)"),
+	"sinh":  doc("x", "sinh returns the hyperbolic sine of x.", "sinh(1)"),
+	"cosh":  doc("x", "cosh returns the hyperbolic cosine of x.", "cosh(1)"),
+	"tanh":  doc("x", "tanh returns the hyperbolic tangent of x.", "tanh(1)"),
+	"asinh": doc("x", "asinh returns the inverse hyperbolic sine of x.", "asinh(1)"),
+	"acosh": doc("x", "acosh returns the inverse hyperbolic cosine of x.", "acosh(2)"),
+	"atanh": doc("x", "atanh returns the inverse hyperbolic tangent of x.", "atanh(0.5)"),
+	"hypot": doc("x, y", "hypot returns sqrt(x**2 + y**2) without needless overflow.", "hypot(3, 4)"),
+	"sign":  doc("x", "sign returns 1 if x is positive, -1 if it is negative, and x itself otherwise.", "sign(-5)"),
+	"gcd":   doc("xs", "gcd returns the greatest common divisor of its integer arguments.", "gcd(12, 18)"),
+	"lcm":   doc("xs", "lcm returns the least common multiple of its integer arguments.", "lcm(4, 6)"),
+}
+
+// LoadMath adds MathFuncs to e.Funcs and MathConsts to e.Consts. Existing
+// names are overwritten.
+func (e *Env) LoadMath() { e.load("math", MathConsts, MathFuncs, mathInfo) }
+
+// round rounds x half away from zero to n decimal places. n may be negative.
+func round(x, n float64) float64 {
+	if math.IsInf(x, 0) || math.IsNaN(x) {
+		return x
+	}
+	p := math.Pow(10, math.Trunc(n))
+	switch {
+	case p == 0:
+		return 0 // x has no digits that far to the left
+	case math.IsInf(p, 0) || math.IsInf(x*p, 0):
+		return x // x has no digits that far to the right
+	}
+	return math.Round(x*p) / p
+}
+
+func minimum(xs []float64) float64 {
+	x := xs[0]
+	for _, v := range xs[1:] {
+		x = math.Min(x, v)
+	}
+	return x
+}
+
+func maximum(xs []float64) float64 {
+	x := xs[0]
+	for _, v := range xs[1:] {
+		x = math.Max(x, v)
+	}
+	return x
+}
+
+func clamp(x, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(x, hi))
+}
+
+func sign(x float64) float64 {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	}
+	return x // ±0 or NaN
+}
+
+func isInteger(x float64) bool {
+	return x == math.Trunc(x) && !math.IsInf(x, 0)
+}
+
+// gcd returns the greatest common divisor of xs, or NaN if any of them is
+// not an integer.
+func gcd(xs []float64) float64 {
+	x := 0.0
+	for _, y := range xs {
+		if !isInteger(y) {
+			return math.NaN()
+		}
+		x = gcd2(x, math.Abs(y))
+	}
+	return x
+}
+
+// gcd2 returns the greatest common divisor of the non-negative integers x
+// and y.
+func gcd2(x, y float64) float64 {
+	for y != 0 {
+		x, y = y, math.Mod(x, y)
+	}
+	return x
+}
+
+// lcm returns the least common multiple of xs, or NaN if any of them is not
+// an integer.
+func lcm(xs []float64) float64 {
+	x := 1.0
+	for _, y := range xs {
+		if !isInteger(y) {
+			return math.NaN()
+		}
+		if x == 0 || y == 0 {
+			x = 0
+			continue
+		}
+		y = math.Abs(y)
+		x = x / gcd2(x, y) * y
+	}
+	return x
+}
@@ -0,0 +1,65 @@
+package sec
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMathFuncsCheck(t *testing.T) {
+	if err := MathFuncs.Check(); err != nil {
+		t.Fatal("expect no error, got", err)
+	}
+}
+
+func TestLoadMath(t *testing.T) {
+	var env Env
+	env.LoadMath()
+
+	cases := map[string]float64{
+		"sqrt(16)":           4,
+		"round(3.14159, 2)":  3.14,
+		"round(-2.5)":        -3,
+		"round(1250, -2)":    1300,
+		"round(1, 400)":      1,
+		"round(2, 308)":      2,
+		"round(1.5, -400)":   0,
+		"min(3, 1, 2)":       1,
+		"max(3)":             3,
+		"clamp(15, 0, 10)":   10,
+		"sign(-2.5)":         -1,
+		"gcd(12, 18, 24)":    6,
+		"lcm(4, 6)":          12,
+		"hypot(3, 4)":        5,
+		"floor(pi)":          3,
+		"log(e)":             1,
+		"atan2(1, 1) * 4":    math.Pi,
+		"cosh(0) + sinh(0)":  1,
+		"trunc(-2.7) + 2":    0,
+		"log2(8) + log10(1)": 3,
+	}
+
+	expectVals(t, env, 1e-12, cases)
+}
+
+func TestGcdNotInteger(t *testing.T) {
+	if !math.IsNaN(gcd([]float64{1.5, 3})) || !math.IsNaN(lcm([]float64{2, 0.5})) {
+		t.Fatal("expect NaN")
+	}
+}
+
+// expectVals evaluates the expressions in cases in env, and compares their
+// values with the expected ones within tolerance.
+func expectVals(t *testing.T, env Env, tolerance float64, cases map[string]float64) {
+	t.Helper()
+	for src, want := range cases {
+		expr, err := Parse(src)
+		if err != nil {
+			t.Fatal(src, err)
+		}
+		if got, err := expr.Val(env); err != nil {
+			t.Fatal(src, err)
+		} else if !(math.Abs(got-want) <= tolerance) {
+			t.Fatalf("%s: expect %v, got %v", src, want, got)
+		}
+	}
+}
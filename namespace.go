@@ -0,0 +1,41 @@
+package sec
+
+import (
+	"strings"
+)
+
+// Namespace returns the namespace of e named name, creating it when it does
+// not exist. The variables and functions of a namespace are referred to by
+// qualified names, like geo.distance for the function distance in the
+// namespace geo, so that packages of functions can be added to an Env
+// without name collisions. A dotted name like a.b names the namespace b in
+// the namespace a.
+//
+// Only the Vars, Funcs, Info, resolvers and namespaces of a namespace are
+// used, functions in it are called with the Env an expression is evaluated
+// in.
+func (e *Env) Namespace(name string) *Env {
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		return e.Namespace(name[:i]).Namespace(name[i+1:])
+	}
+	if e.namespaces == nil {
+		e.namespaces = make(map[string]*Env)
+	}
+	ns, ok := e.namespaces[name]
+	if !ok {
+		ns = &Env{Vars: Vars{}, Funcs: Funcs{}}
+		e.namespaces[name] = ns
+	}
+	return ns
+}
+
+// namespace splits a qualified name at its first dot, and returns the
+// namespace of e named by the first part with the rest of the name. It
+// returns a nil namespace when there is no such namespace.
+func (e *Env) namespace(name string) (ns *Env, rest string) {
+	i := strings.IndexByte(name, '.')
+	if i < 0 {
+		return nil, ""
+	}
+	return e.namespaces[name[:i]], name[i+1:]
+}
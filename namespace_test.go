@@ -0,0 +1,74 @@
+package sec
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNamespace(t *testing.T) {
+	env := Env{Vars: Vars{"x": 3, "geo.y": 1}, Funcs: Funcs{}}
+	env.Funcs.Add("distance", func(x float64) float64 { return x })
+
+	geo := env.Namespace("geo")
+	geo.Vars["y"] = 4
+	geo.Vars["scale"] = 10
+	geo.Register("distance", func(x, y float64) float64 { return x*x + y*y }, FuncInfo{Params: []string{"x", "y"}})
+	env.Namespace("geo.units").Vars["km"] = 1000
+	env.Namespace("math").LoadMath()
+
+	for src, expected := range map[string]float64{
+		"geo.distance(x, geo.scale)":     109,
+		"distance(x)":                    3,
+		"geo.y":                          1, // the full name comes first
+		"geo.units.km":                   1000,
+		"math.max(math.floor(x / 2), 0)": 1,
+	} {
+		expr, err := Parse(src)
+		if err != nil {
+			t.Fatal(src, err)
+		}
+		if val, err := expr.Val(env); err != nil {
+			t.Fatal(src, err)
+		} else if val != expected {
+			t.Fatalf("%s: expect %v, got %v", src, expected, val)
+		}
+	}
+
+	for _, src := range []string{"geo.x", "scale", "max(1, 2)", "units.km", "geo.distance(1)"} {
+		expr, _ := Parse(src)
+		if _, err := expr.Val(env); err == nil {
+			t.Fatalf("%s: expect an error", src)
+		}
+	}
+
+	if geo != env.Namespace("geo") {
+		t.Fatal("expect Namespace to return the existing namespace")
+	}
+
+	var found bool
+	for _, desc := range env.Functions() {
+		if desc.Name == "geo.distance" {
+			found = desc.Signature == "geo.distance(x, y)"
+		}
+	}
+	if !found {
+		t.Fatal("expect geo.distance in Functions, got", env.Functions())
+	}
+}
+
+func TestNamespacedFuncEnv(t *testing.T) {
+	env := Env{Vars: Vars{"rate": 2}}
+	env.Namespace("tenant").Funcs["rate"] = builtin{0, 0, func(env Env, _ []float64) (float64, error) {
+		val, _ := env.Lookup("rate")
+		return val, nil
+	}}
+
+	child := env.Child()
+	child.Vars["rate"] = 3
+	expr, _ := Parse("tenant.rate()")
+	if val, err := expr.ValContext(context.Background(), child); err != nil {
+		t.Fatal(err)
+	} else if val != 3 {
+		t.Fatal("expect functions in namespaces to be called with the root Env, got", val)
+	}
+}
@@ -0,0 +1,114 @@
+package sec
+
+import (
+	"math"
+	"strconv"
+)
+
+// Optimize returns e simplified for evaluations in env, or in Envs with the
+// same Consts, functions and options. It
+//
+//   - inlines the constants of env,
+//   - folds operations on constants, like 2*3 into 6,
+//   - applies the identities x*1, 1*x, x/1, x**1, x+0, 0+x and x-0,
+//   - evaluates calls of functions marked pure in their FuncInfo, when all
+//     arguments are constants.
+//
+// Operations which fail or yield an infinity or NaN are kept, so that they
+// fail or overflow when evaluated as before.
+func Optimize(e Expr, env Env) Expr {
+	switch n := e.(type) {
+	case Variable:
+		if val, ok := env.constant(n.txt); ok {
+			return Constant{token(n), val}
+		}
+	case Unary:
+		n.expr = Optimize(n.expr, env)
+		if n.Op() == OpAdd {
+			return n.expr
+		}
+		if x, ok := constValue(n.expr); ok {
+			if folded, ok := fold(n.op.Position, -x); ok {
+				return folded
+			}
+		}
+		return n
+	case Binary:
+		n.l, n.r = Optimize(n.l, env), Optimize(n.r, env)
+		if x, ok := constValue(n.l); ok {
+			if _, ok := constValue(n.r); ok {
+				if val, err := n.Val(env); err == nil {
+					if folded, ok := fold(n.op.Position, val); ok {
+						return folded
+					}
+				}
+				return n
+			}
+			if x == 0 && n.Op() == OpAdd || x == 1 && n.Op() == OpMul {
+				return n.r
+			}
+		}
+		if y, ok := constValue(n.r); ok {
+			switch {
+			case y == 0 && (n.Op() == OpAdd || n.Op() == OpSub),
+				y == 1 && (n.Op() == OpMul || n.Op() == OpDiv || n.Op() == OpPow):
+				return n.l
+			}
+		}
+		return n
+	case Call:
+		args := make([]Expr, len(n.args))
+		constArgs := true
+		for i, arg := range n.args {
+			args[i] = Optimize(arg, env)
+			_, ok := constValue(args[i])
+			constArgs = constArgs && ok
+		}
+		n.args = args
+		if constArgs && env.funcInfo(n.txt).Pure {
+			if val, err := n.Val(env); err == nil {
+				if folded, ok := fold(n.token.Position, val); ok {
+					return folded
+				}
+			}
+		}
+		return n
+	}
+	return e
+}
+
+// constValue returns the value of e if it is a constant, a literal or a
+// negated literal.
+func constValue(e Expr) (float64, bool) {
+	switch e := e.(type) {
+	case Literal:
+		return e.val, true
+	case Constant:
+		return e.val, true
+	case Unary:
+		if lit, ok := e.expr.(Literal); ok && e.Op() == OpSub {
+			return -lit.val, true
+		}
+	}
+	return 0, false
+}
+
+// fold makes a literal of val at pos, negated if val is negative. It fails
+// for infinities and NaN, which have no literals.
+func fold(pos Position, val float64) (Expr, bool) {
+	if math.IsInf(val, 0) || math.IsNaN(val) {
+		return nil, false
+	}
+	neg := math.Signbit(val)
+	txt := strconv.FormatFloat(math.Abs(val), 'f', -1, 64)
+	lit := Literal{token{Position: pos, typ: integer, txt: txt}, math.Abs(val)}
+	for _, ch := range txt {
+		if ch == '.' {
+			lit.typ = float
+		}
+	}
+	if !neg {
+		return lit, true
+	}
+	return Unary{token{Position: pos, typ: minus, txt: "-"}, lit}, true
+}
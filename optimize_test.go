@@ -0,0 +1,70 @@
+package sec
+
+import (
+	"testing"
+)
+
+func TestOptimize(t *testing.T) {
+	env := Env{Consts: Vars{"k": 4}, Vars: Vars{"x": 3}, Funcs: Funcs{}}
+	env.LoadMath()
+	env.Register("sq", Func1(func(x float64) float64 { return x * x }), FuncInfo{Pure: true})
+	env.Namespace("geo").Register("area", Func2(func(w, h float64) float64 { return w * h }), FuncInfo{Pure: true})
+	var calls int
+	env.Funcs["tick"] = Func1(func(x float64) float64 { calls++; return x })
+
+	cases := map[string]string{
+		"2*3+x":                "6 + x",
+		"x*1 + 0 + 1*x - 0":    "x + x",
+		"x/1 ** 1":             "x",
+		"x ** 1 ** 2":          "x ** 2",
+		"+x":                   "x",
+		"-(2+3) * x":           "-5 * x",
+		"--5 + x":              "5 + x",
+		"1 - 3":                "-2",
+		"k * x":                "k * x", // printed by name, but inlined
+		"0x10 + 0.5":           "16.5",
+		"sq(k - 1) + sq(x)":    "9 + sq(x)",
+		"geo.area(2, 3)":       "6",
+		"tick(1 + 1)":          "tick(2)",
+		"1 / 0 + x":            "1 / 0 + x",
+		"max(1, 2)":            "2",
+		"sqrt(16) * x":         "4 * x",
+		"sin(0) + x":           "sin(0) + x", // depends on the AngleMode
+		"undeclared(1) * 1":    "undeclared(1)",
+		"0 ** 0.5 + 10 // 4":   "2",
+		"sq(sq(2), 1) + sq(2)": "sq(4, 1) + 4",
+	}
+	for src, expected := range cases {
+		expr, err := Parse(src)
+		if err != nil {
+			t.Fatal(src, err)
+		}
+		opt := Optimize(expr, env)
+		if str := opt.String(); str != expected {
+			t.Fatalf("%s: expect %q, got %q", src, expected, str)
+		}
+
+		want, werr := expr.Val(env)
+		got, gerr := opt.Val(env)
+		if (werr == nil) != (gerr == nil) || werr == nil && want != got {
+			t.Fatalf("%s: expect %v, %v, got %v, %v", src, want, werr, got, gerr)
+		}
+	}
+	expr, _ := Parse("k")
+	if _, ok := Optimize(expr, env).(Constant); !ok {
+		t.Fatal("expect constants of the Env to be inlined")
+	}
+	if calls == 0 {
+		t.Fatal("expect functions not marked pure to be left to evaluations")
+	}
+}
+
+func BenchmarkOptimized(b *testing.B) {
+	env := Env{Vars: Vars{"x": 3}}
+	env.LoadMath()
+	expr, _ := Parse("x * (2 ** 10 - 1) / pi + 0x10 * 1")
+	opt := Optimize(expr, env)
+	for i := 0; i < b.N; i++ {
+		opt.Val(env)
+	}
+}
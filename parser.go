@@ -5,8 +5,18 @@ import (
 )
 
 type Parser struct {
+	// MaxDepth limits how deeply expressions may nest, MaxTokens limits the
+	// number of tokens in the source. Zero means no limit.
+	MaxDepth, MaxTokens int
+	// Consts are inlined into expressions as they are parsed, the Consts
+	// of an Env for example. Expressions parsed with them no longer look
+	// the constants up when evaluated.
+	Consts Vars
+
 	tokenReader tokenReader
 	token       token // current token
+	depth       int   // current nesting depth
+	tokens      int   // number of tokens read
 }
 
 func (p *Parser) next() {
@@ -15,8 +25,21 @@ func (p *Parser) next() {
 	if err != nil && err != io.EOF {
 		panic(err)
 	}
+	if p.tokens++; p.MaxTokens > 0 && p.tokens > p.MaxTokens && p.token.typ != EOF {
+		panic(secError{ErrTokenLimit{p.token.Position}})
+	}
+}
+
+// enter starts parsing a nested expression.
+func (p *Parser) enter() {
+	if p.depth++; p.MaxDepth > 0 && p.depth > p.MaxDepth {
+		panic(secError{ErrParseDepthLimit{p.token.Position}})
+	}
 }
 
+// leave finishes parsing a nested expression.
+func (p *Parser) leave() { p.depth-- }
+
 func (p *Parser) Parse(s string) (ast Expr, err error) {
 	defer func() {
 		switch er := recover().(type) {
@@ -28,6 +51,7 @@ func (p *Parser) Parse(s string) (ast Expr, err error) {
 		}
 	}()
 
+	p.depth, p.tokens = 0, 0
 	p.tokenReader.load(s)
 	p.next()
 	ast = p.parseAddition()
@@ -48,7 +72,7 @@ func (p *Parser) parseAddition() Expr {
 			op := p.token
 			p.next() // consume operator
 			right := p.parseMultiplication()
-			left = binary{op, left, right}
+			left = Binary{op, left, right}
 		default:
 			return left
 		}
@@ -64,7 +88,7 @@ func (p *Parser) parseMultiplication() Expr {
 			op := p.token
 			p.next() // consume operator
 			right := p.parseExponentiation()
-			left = binary{op, left, right}
+			left = Binary{op, left, right}
 		default:
 			return left
 		}
@@ -82,7 +106,7 @@ func (p *Parser) parseExponentiation() Expr {
 		op := p.token
 		p.next() // consume operator
 		right := p.parseUnary()
-		left = binary{op, left, right}
+		left = Binary{op, left, right}
 	}
 
 	return left
@@ -94,13 +118,17 @@ func (p *Parser) parseUnary() Expr {
 	if p.token.typ == plus || p.token.typ == minus {
 		op := p.token
 		p.next() // consume operator
-		return unary{op, p.parseUnary()}
+		p.enter()
+		defer p.leave()
+		return Unary{op, p.parseUnary()}
 	}
 	return p.parsePrimary()
 }
 
 // Primary = identifier
+//         | constant
 //         | number
+//         | number ('rad' | 'deg' | 'grad')
 //         | identifier '(' Additive ')'
 //         | '(' Additive ')'
 func (p *Parser) parsePrimary() Expr {
@@ -111,9 +139,14 @@ func (p *Parser) parsePrimary() Expr {
 		id := p.token
 		p.next() // consume identifier
 		if p.token.typ != lBracket {
-			return variable(id)
+			if val, ok := p.Consts[id.txt]; ok {
+				return Constant{id, val}
+			}
+			return Variable(id)
 		}
 		p.next() // consume '('
+		p.enter()
+		defer p.leave()
 		var args []Expr
 		if p.token.typ != rBracket {
 			for {
@@ -129,13 +162,21 @@ func (p *Parser) parsePrimary() Expr {
 			}
 		}
 		p.next() // consume ')'
-		return call{id, args}
+		return Call{id, args}
 	case integer, float, binLiteral, octLiteral, hexLiteral:
 		token := p.token
 		p.next()
-		return literal(token)
+		if p.token.typ == identifier && !p.token.afterBlank && !p.token.afterNewLine {
+			if unit, ok := angleUnits[p.token.txt]; ok {
+				p.next() // consume unit
+				return Angle{newLiteral(token), unit}
+			}
+		}
+		return newLiteral(token)
 	case lBracket:
 		p.next() // consume '('
+		p.enter()
+		defer p.leave()
 		e := p.parseAddition()
 		if p.token.typ != rBracket {
 			panic(secError{ErrUnexpected{p.token.Position, []rune(p.token.txt)[0]}})
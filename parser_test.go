@@ -1,7 +1,9 @@
 package sec
 
 import (
+	"errors"
 	"math"
+	"strings"
 	"testing"
 )
 
@@ -22,3 +24,51 @@ func TestParse(t *testing.T) {
 		},
 	}))
 }
+
+func TestParseLimits(t *testing.T) {
+	psr := Parser{MaxDepth: 100}
+	if _, err := psr.Parse(strings.Repeat("(", 100) + "1" + strings.Repeat(")", 100)); err != nil {
+		t.Fatal("expect no error, got", err)
+	}
+	var derr ErrParseDepthLimit
+	for _, src := range []string{
+		strings.Repeat("(", 100000),
+		strings.Repeat("-", 101) + "1",
+		strings.Repeat("f(", 101),
+	} {
+		if _, err := psr.Parse(src); !errors.As(err, &derr) {
+			t.Fatal("expect ErrParseDepthLimit error, got", err)
+		}
+	}
+
+	psr = Parser{MaxTokens: 5}
+	if _, err := psr.Parse("1 + 2 + 3"); err != nil {
+		t.Fatal("expect no error, got", err)
+	}
+	var terr ErrTokenLimit
+	if _, err := psr.Parse("1 + 2 + 3 + 4"); !errors.As(err, &terr) {
+		t.Fatal("expect ErrTokenLimit error, got", err)
+	} else if terr.Position != (Position{1, 11}) {
+		t.Fatal("position not correct:", terr.Position)
+	}
+}
+
+func TestParseConsts(t *testing.T) {
+	psr := Parser{Consts: Vars{"tax_rate_2026": 0.2}}
+	expr, err := psr.Parse("price * (1 + tax_rate_2026) + tax_rate_2026(1)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the constant is no longer looked up, while the function of the same
+	// name still is
+	env := Env{
+		Vars:  Vars{"price": 10, "tax_rate_2026": 1},
+		Funcs: Funcs{"tax_rate_2026": Func1(func(x float64) float64 { return x })},
+	}
+	if val, err := expr.Val(env); err != nil {
+		t.Fatal(err)
+	} else if val != 13 {
+		t.Fatal("expect 13, got", val)
+	}
+}
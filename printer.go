@@ -0,0 +1,149 @@
+package sec
+
+import (
+	"strings"
+)
+
+// Printer prints expressions in a canonical form. It adds only the
+// parentheses the precedence of operators requires and keeps literals as
+// written, so parsing the output gives the same tree.
+type Printer struct {
+	// Compact omits the spaces around binary operators and after commas.
+	Compact bool
+	// Width is the preferred width of lines. Lines longer than it are
+	// broken after binary operators and commas, and continued with
+	// Indent. Zero means no limit.
+	Width int
+	// Indent is prepended to continued lines, four spaces if empty.
+	Indent string
+}
+
+// piece is a piece of the output, which may be followed by a line break.
+type piece struct {
+	text string
+	brk  bool
+}
+
+// Precedences of the operators. Binary operators are all left-associative,
+// and unary operators bind tighter than any of them.
+const (
+	precAdd = iota + 1
+	precMul
+	precPow
+	precUnary
+	precPrimary
+)
+
+func (op Op) precedence() int {
+	switch op {
+	case OpAdd, OpSub:
+		return precAdd
+	case OpMul, OpDiv, OpMod, OpFloorDiv:
+		return precMul
+	}
+	return precPow
+}
+
+func precedence(e Expr) int {
+	switch e := e.(type) {
+	case Binary:
+		return e.Op().precedence()
+	case Unary:
+		return precUnary
+	}
+	return precPrimary
+}
+
+// Sprint returns e printed.
+func (p Printer) Sprint(e Expr) string {
+	var pieces []piece
+	p.pieces(e, &pieces)
+
+	indent := p.Indent
+	if indent == "" {
+		indent = "    "
+	}
+	sep := " "
+	if p.Compact {
+		sep = ""
+	}
+
+	var b strings.Builder
+	col := 0
+	for i := 0; i < len(pieces); i++ {
+		if i > 0 && pieces[i-1].brk {
+			// the length of the run of pieces up to the next break
+			n := 0
+			for j := i; j < len(pieces); j++ {
+				if n += len(pieces[j].text); pieces[j].brk {
+					break
+				}
+			}
+			if p.Width > 0 && col > len(indent) && col+len(sep)+n > p.Width {
+				b.WriteString("\n" + indent)
+				col = len(indent)
+			} else {
+				b.WriteString(sep)
+				col += len(sep)
+			}
+		}
+		b.WriteString(pieces[i].text)
+		col += len(pieces[i].text)
+	}
+	return b.String()
+}
+
+// pieces appends the pieces of e to out.
+func (p Printer) pieces(e Expr, out *[]piece) {
+	switch e := e.(type) {
+	case Binary:
+		op := e.Op()
+		p.operand(e.l, precedence(e.l) < op.precedence(), out)
+		if p.Compact {
+			*out = append(*out, piece{op.String(), true})
+		} else {
+			*out = append(*out, piece{" " + op.String(), true})
+		}
+		p.operand(e.r, precedence(e.r) <= op.precedence(), out)
+	case Unary:
+		*out = append(*out, piece{text: e.Op().String()})
+		p.operand(e.expr, precedence(e.expr) < precUnary, out)
+	case Call:
+		*out = append(*out, piece{text: e.txt + "("})
+		for i, arg := range e.args {
+			if i > 0 {
+				*out = append(*out, piece{",", true})
+			}
+			p.pieces(arg, out)
+		}
+		*out = append(*out, piece{text: ")"})
+	case Variable:
+		*out = append(*out, piece{text: e.txt})
+	case Constant:
+		*out = append(*out, piece{text: e.txt})
+	case Literal:
+		*out = append(*out, piece{text: e.txt})
+	case Angle:
+		*out = append(*out, piece{text: e.lit.txt + e.unit.String()})
+	}
+}
+
+// operand appends the pieces of the operand e to out, in parentheses if
+// paren is true.
+func (p Printer) operand(e Expr, paren bool, out *[]piece) {
+	if !paren {
+		p.pieces(e, out)
+		return
+	}
+	*out = append(*out, piece{text: "("})
+	p.pieces(e, out)
+	*out = append(*out, piece{text: ")"})
+}
+
+func (v Variable) String() string { return Printer{}.Sprint(v) }
+func (c Constant) String() string { return Printer{}.Sprint(c) }
+func (l Literal) String() string  { return Printer{}.Sprint(l) }
+func (a Angle) String() string    { return Printer{}.Sprint(a) }
+func (u Unary) String() string    { return Printer{}.Sprint(u) }
+func (b Binary) String() string   { return Printer{}.Sprint(b) }
+func (c Call) String() string     { return Printer{}.Sprint(c) }
@@ -0,0 +1,84 @@
+package sec
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// shape describes the tree of e without positions.
+func shape(e Expr) string {
+	var b strings.Builder
+	Inspect(e, func(node Expr) bool {
+		switch n := node.(type) {
+		case nil:
+			b.WriteString(")")
+		case Binary:
+			fmt.Fprintf(&b, "(binary %s", n.Op())
+		case Unary:
+			fmt.Fprintf(&b, "(unary %s", n.Op())
+		case Call:
+			fmt.Fprintf(&b, "(call %s", n.Name())
+		case Literal:
+			fmt.Fprintf(&b, "(literal %s", n.Text())
+		default:
+			fmt.Fprintf(&b, "(%T %s", n, n)
+		}
+		return true
+	})
+	return b.String()
+}
+
+func TestString(t *testing.T) {
+	psr := Parser{Consts: Vars{"pi": 3.14}}
+	cases := map[string]string{
+		"1+2*3":                  "1 + 2 * 3",
+		"(1+2)*3":                "(1 + 2) * 3",
+		"1-(2-3)":                "1 - (2 - 3)",
+		"(1-2)-3":                "1 - 2 - 3",
+		"2**(3**2)":              "2 ** (3 ** 2)",
+		"(2**3)**2":              "2 ** 3 ** 2",
+		"-(x)**2":                "-x ** 2",
+		"-(x**2)":                "-(x ** 2)",
+		"2**-x":                  "2 ** -x",
+		"a - -b":                 "a - -b",
+		"- - (a)":                "--a",
+		"0xFF + 0b101 + 0755":    "0xFF + 0b101 + 0755",
+		"1. + 3.140":             "1. + 3.140",
+		"max( a,(b) , 30deg )":   "max(a, b, 30deg)",
+		"pi*r**2 // (a % b / c)": "pi * r ** 2 // (a % b / c)",
+		"f()":                    "f()",
+	}
+	for src, expected := range cases {
+		expr, err := psr.Parse(src)
+		if err != nil {
+			t.Fatal(src, err)
+		}
+		if str := expr.String(); str != expected {
+			t.Fatalf("%s: expect %q, got %q", src, expected, str)
+		}
+		for _, p := range []Printer{{}, {Compact: true}, {Width: 4}, {Compact: true, Width: 1, Indent: "\t"}} {
+			str := p.Sprint(expr)
+			again, err := psr.Parse(str)
+			if err != nil {
+				t.Fatalf("%s: %+v printed %q: %v", src, p, str, err)
+			}
+			if shape(again) != shape(expr) {
+				t.Fatalf("%s: %+v printed %q, parsed to %s", src, p, str, shape(again))
+			}
+		}
+	}
+}
+
+func TestPrinterStyle(t *testing.T) {
+	expr, _ := Parse("max(price*qty, 10) - -discount")
+	if str := (Printer{Compact: true}).Sprint(expr); str != "max(price*qty,10)--discount" {
+		t.Fatal("unexpected", str)
+	}
+
+	expr, _ = Parse("alpha + beta * gamma - f(delta, epsilon) + zeta")
+	expected := "alpha + beta * gamma -\n  f(delta, epsilon) +\n  zeta"
+	if str := (Printer{Width: 22, Indent: "  "}).Sprint(expr); str != expected {
+		t.Fatalf("expect %q, got %q", expected, str)
+	}
+}
@@ -0,0 +1,83 @@
+package sec
+
+import (
+	"math"
+	"math/rand"
+)
+
+// RandFuncs is a set of random number functions. They draw from Env.Rand, so
+// an evaluation can be replayed exactly by seeding it the same way:
+//
+//	rand()             uniform in [0, 1)
+//	randInt(a, b)      uniform integer in [a, b]
+//	normalRand(mu, sd) normally distributed
+//	choice(x, ...)     one of its arguments
+var RandFuncs = Funcs{
+	"rand":       builtin{0, 0, randFloat},
+	"randInt":    builtin{2, 2, randInt},
+	"normalRand": builtin{2, 2, normalRand},
+	"choice":     builtin{1, -1, choice},
+}
+
+// randInfo documents RandFuncs.
+var randInfo = map[string]FuncInfo{
+	"rand":       envDoc("", "rand returns a uniformly distributed number in [0, 1).", "rand()"),
+	"randInt":    envDoc("a, b", "randInt returns a uniformly distributed integer in [a, b].", "randInt(1, 6)"),
+	"normalRand": envDoc("mu, sigma", "normalRand returns a normally distributed number.", "normalRand(0, 1)"),
+	"choice":     envDoc("xs", "choice returns one of its arguments at random.", "choice(1, 2, 3)"),
+}
+
+// LoadRand adds RandFuncs to e. Existing names are overwritten.
+func (e *Env) LoadRand() { e.load("random", nil, RandFuncs, randInfo) }
+
+// Seed sets e.Rand to a new source seeded with seed.
+func (e *Env) Seed(seed int64) { e.Rand = rand.New(rand.NewSource(seed)) }
+
+// source is the part of *rand.Rand the random functions use.
+type source interface {
+	Float64() float64
+	Int63n(n int64) int64
+	NormFloat64() float64
+	Intn(n int) int
+}
+
+// globalSource draws from the shared source of the math/rand package.
+type globalSource struct{}
+
+func (globalSource) Float64() float64     { return rand.Float64() }
+func (globalSource) Int63n(n int64) int64 { return rand.Int63n(n) }
+func (globalSource) NormFloat64() float64 { return rand.NormFloat64() }
+func (globalSource) Intn(n int) int       { return rand.Intn(n) }
+
+func (e Env) source() source {
+	if e.Rand == nil {
+		return globalSource{}
+	}
+	return e.Rand
+}
+
+func randFloat(env Env, _ []float64) (float64, error) {
+	return env.source().Float64(), nil
+}
+
+func randInt(env Env, args []float64) (float64, error) {
+	a, b := args[0], args[1]
+	for i, arg := range args {
+		if !isInteger(arg) || math.Abs(arg) > 1<<53 {
+			return 0, ErrArgNotInteger{i + 1}
+		}
+	}
+	if a > b {
+		return 0, ErrArgOutOfRange{2}
+	}
+	return a + float64(env.source().Int63n(int64(b-a)+1)), nil
+}
+
+func normalRand(env Env, args []float64) (float64, error) {
+	mu, sigma := args[0], args[1]
+	return mu + sigma*env.source().NormFloat64(), nil
+}
+
+func choice(env Env, args []float64) (float64, error) {
+	return args[env.source().Intn(len(args))], nil
+}
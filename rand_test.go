@@ -0,0 +1,73 @@
+package sec
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRandFuncsCheck(t *testing.T) {
+	if err := RandFuncs.Check(); err != nil {
+		t.Fatal("expect no error, got", err)
+	}
+}
+
+func TestRandReplay(t *testing.T) {
+	expr, err := Parse("rand() + randInt(1, 6) + normalRand(10, 2) + choice(1, 2, 3)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(seed int64) (vals []float64) {
+		var env Env
+		env.LoadRand()
+		env.Seed(seed)
+		for i := 0; i < 10; i++ {
+			val, err := expr.Val(env)
+			if err != nil {
+				t.Fatal(err)
+			}
+			vals = append(vals, val)
+		}
+		return
+	}
+
+	a, b := run(114514), run(114514)
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("expect same values with same seed, got %v and %v", a[i], b[i])
+		}
+	}
+}
+
+func TestRandInt(t *testing.T) {
+	var env Env
+	env.LoadRand()
+	env.Seed(1)
+
+	expr, _ := Parse("randInt(-2, 2)")
+	for i := 0; i < 100; i++ {
+		val, err := expr.Val(env)
+		if err != nil {
+			t.Fatal(err)
+		} else if val < -2 || val > 2 || !isInteger(val) {
+			t.Fatal("value out of range:", val)
+		}
+	}
+
+	expr, _ = Parse("randInt(3, 1)")
+	if _, err := expr.Val(env); !errors.Is(err, ErrArgOutOfRange{2}) {
+		t.Fatal("expect ErrArgOutOfRange error, got", err)
+	}
+}
+
+func TestRandWithoutSource(t *testing.T) {
+	var env Env
+	env.LoadRand()
+
+	expr, _ := Parse("rand()")
+	if val, err := expr.Val(env); err != nil {
+		t.Fatal(err)
+	} else if val < 0 || val >= 1 {
+		t.Fatal("value out of range:", val)
+	}
+}
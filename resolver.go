@@ -0,0 +1,131 @@
+package sec
+
+type (
+	// VarResolver resolves the values of variables by name.
+	VarResolver interface {
+		Lookup(name string) (val float64, ok bool)
+	}
+
+	// FuncResolver resolves functions by name. The function may be of any
+	// form accepted in Funcs.
+	FuncResolver interface {
+		LookupFunc(name string) (fun interface{}, ok bool)
+	}
+
+	// VarResolverFunc is an adapter to use an ordinary function as a
+	// VarResolver.
+	VarResolverFunc func(name string) (val float64, ok bool)
+
+	// FuncResolverFunc is an adapter to use an ordinary function as a
+	// FuncResolver.
+	FuncResolverFunc func(name string) (fun interface{}, ok bool)
+)
+
+func (f VarResolverFunc) Lookup(name string) (float64, bool) { return f(name) }
+
+func (f FuncResolverFunc) LookupFunc(name string) (interface{}, bool) { return f(name) }
+
+func (v Vars) Lookup(name string) (val float64, ok bool) {
+	val, ok = v[name]
+	return
+}
+
+func (f Funcs) LookupFunc(name string) (fun interface{}, ok bool) {
+	fun, ok = f[name]
+	return
+}
+
+// Lookup resolves a variable in e.Vars, then by e.VarResolver, then in the
+// namespaces of e, then in the parent of e. The Consts of e and its
+// ancestors take precedence over all of them, and the names of a frozen
+// ancestor, its Consts included, over everything.
+func (e Env) Lookup(name string) (val float64, ok bool) { return e.lookup(name) }
+
+// lookup is Lookup without copying e.
+func (e *Env) lookup(name string) (val float64, ok bool) {
+	frozen := e.frozenAncestor()
+	if frozen != nil {
+		if val, ok = frozen.lookup(name); ok {
+			return
+		}
+	}
+	if val, ok = e.lookupConst(name, frozen); ok {
+		return
+	}
+	if val, ok = e.Vars[name]; ok {
+		return
+	}
+	if e.VarResolver != nil {
+		if val, ok = e.VarResolver.Lookup(name); ok {
+			return
+		}
+	}
+	if ns, rest := e.namespace(name); ns != nil {
+		if val, ok = ns.lookup(rest); ok {
+			return
+		}
+	}
+	if e.parent != nil && e.parent != frozen {
+		return e.parent.lookup(name)
+	}
+	return
+}
+
+// LookupFunc resolves a function in e.Funcs, then by e.FuncResolver, then in
+// the namespaces of e, then in the parent of e. Names of a frozen ancestor
+// take precedence over all of them.
+func (e Env) LookupFunc(name string) (fun interface{}, ok bool) { return e.lookupFunc(name) }
+
+// lookupFunc is LookupFunc without copying e.
+func (e *Env) lookupFunc(name string) (fun interface{}, ok bool) {
+	frozen := e.frozenAncestor()
+	if frozen != nil {
+		if fun, ok = frozen.lookupFunc(name); ok {
+			return
+		}
+	}
+	if fun, ok = e.Funcs[name]; ok {
+		return
+	}
+	if e.FuncResolver != nil {
+		if fun, ok = e.FuncResolver.LookupFunc(name); ok {
+			return
+		}
+	}
+	if ns, rest := e.namespace(name); ns != nil {
+		if fun, ok = ns.lookupFunc(rest); ok {
+			return
+		}
+	}
+	if e.parent != nil && e.parent != frozen {
+		return e.parent.lookupFunc(name)
+	}
+	return
+}
+
+// lookupConst resolves a constant in the Consts of e and its ancestors below
+// stop, the outermost first.
+func (e *Env) lookupConst(name string, stop *Env) (val float64, ok bool) {
+	if e.parent != nil && e.parent != stop {
+		if val, ok = e.parent.lookupConst(name, stop); ok {
+			return
+		}
+	}
+	val, ok = e.Consts[name]
+	return
+}
+
+// constant resolves name like lookup does, and reports whether it resolves
+// to one of the Consts.
+func (e *Env) constant(name string) (val float64, ok bool) {
+	frozen := e.frozenAncestor()
+	if frozen != nil {
+		if val, ok = frozen.constant(name); ok {
+			return
+		}
+		if _, found := frozen.lookup(name); found {
+			return 0, false
+		}
+	}
+	return e.lookupConst(name, frozen)
+}
@@ -0,0 +1,77 @@
+package sec
+
+import (
+	"math"
+	"testing"
+)
+
+func TestResolvers(t *testing.T) {
+	row := map[string]float64{"price": 12.5, "qty": 4}
+	var lookups int
+	env := Env{
+		Vars: Vars{"qty": 2},
+		VarResolver: VarResolverFunc(func(name string) (val float64, ok bool) {
+			lookups++
+			val, ok = row[name]
+			return
+		}),
+		FuncResolver: Funcs{"half": Func1(func(x float64) float64 { return x / 2 })},
+	}
+
+	expr, _ := Parse("half(price * qty)")
+	if val, err := expr.Val(env); err != nil {
+		t.Fatal(err)
+	} else if val != 12.5 {
+		t.Fatal("expect 12.5, got", val)
+	} else if lookups != 1 {
+		t.Fatal("expect Vars to be consulted before the resolver")
+	}
+
+	expr, _ = Parse("discount")
+	if _, err := expr.Val(env); err == nil {
+		t.Fatal("expect ErrUndeclaredVar error")
+	} else if _, ok := err.(ErrUndeclaredVar); !ok {
+		t.Fatal("expect ErrUndeclaredVar error, got", err)
+	}
+
+	expr, _ = Parse("double(1)")
+	if _, err := expr.Val(env); err == nil {
+		t.Fatal("expect ErrUndeclaredFunc error")
+	} else if _, ok := err.(ErrUndeclaredFunc); !ok {
+		t.Fatal("expect ErrUndeclaredFunc error, got", err)
+	}
+}
+
+func TestEnvAsResolver(t *testing.T) {
+	base := Env{Vars: Vars{"a": 1}, Funcs: Funcs{"one": Func0(func() float64 { return 1 })}}
+	env := Env{Vars: Vars{"b": 2}, VarResolver: base, FuncResolver: base}
+
+	expr, _ := Parse("a + b + one()")
+	if val, err := expr.Val(env); err != nil {
+		t.Fatal(err)
+	} else if val != 4 {
+		t.Fatal("expect 4, got", val)
+	}
+}
+
+func TestConsts(t *testing.T) {
+	base := Env{}
+	base.LoadMath()
+	base.Vars = Vars{"pi": 3, "r": 2}
+	if _, ok := base.Vars["e"]; ok {
+		t.Fatal("expect LoadMath to add constants to Consts")
+	}
+
+	child := base.WithVars(Vars{"pi": 3, "r": 1})
+	child.Consts = Vars{"pi": 3, "tau": 2 * math.Pi}
+	for name, expected := range map[string]float64{"pi": math.Pi, "tau": 2 * math.Pi, "r": 1} {
+		if val, _ := child.Lookup(name); val != expected {
+			t.Fatalf("%s: expect %v, got %v", name, expected, val)
+		}
+	}
+
+	base.VarResolver = VarResolverFunc(func(string) (float64, bool) { return 0, true })
+	if val, _ := base.Lookup("phi"); val != math.Phi {
+		t.Fatal("expect Consts to take precedence over resolvers, got", val)
+	}
+}
@@ -0,0 +1,60 @@
+package sec
+
+// Child returns a new Env overlaying e. Names not found in the child are
+// looked up in e, while names added to the child's Vars and Funcs stay in
+// the child. The child inherits the AngleMode and EvalOptions of e, but not
+// its Rand, which is not safe for concurrent use: the random functions of a
+// child draw from the shared source of the math/rand package unless it is
+// given its own, by Seed for example.
+//
+// The child sees later changes to the Vars and Funcs of e, but not to its
+// other fields.
+func (e Env) Child() Env {
+	c := e.WithVars(Vars{})
+	c.Funcs = Funcs{}
+	return c
+}
+
+// WithVars is like Child, but uses vars as the Vars of the child without
+// copying it, and leaves the Funcs of the child nil.
+func (e Env) WithVars(vars Vars) Env {
+	parent := e
+	return Env{
+		Vars:        vars,
+		AngleMode:   e.AngleMode,
+		EvalOptions: e.EvalOptions,
+		parent:      &parent,
+	}
+}
+
+// Freeze makes the names of e take precedence over the names of children
+// created from it afterwards, so they can not be shadowed.
+func (e *Env) Freeze() { e.frozen = true }
+
+// frozenAncestor returns the nearest frozen ancestor of e, or nil.
+func (e *Env) frozenAncestor() *Env {
+	for p := e.parent; p != nil; p = p.parent {
+		if p.frozen {
+			return p
+		}
+	}
+	return nil
+}
+
+// find returns the first scope, in the order names are looked up, for which
+// has returns true, or nil.
+func (e *Env) find(has func(s *Env) bool) *Env {
+	frozen := e.frozenAncestor()
+	if frozen != nil {
+		if s := frozen.find(has); s != nil {
+			return s
+		}
+	}
+	if has(e) {
+		return e
+	}
+	if e.parent != nil && e.parent != frozen {
+		return e.parent.find(has)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package sec
+
+import (
+	"testing"
+)
+
+func TestChild(t *testing.T) {
+	base := Env{Vars: Vars{"a": 1, "b": 2}, Funcs: Funcs{}}
+	base.Funcs.Add("double", func(x float64) float64 { return x * 2 })
+	base.AngleMode = Degrees
+	base.MaxSteps = 100
+	base.Seed(1)
+
+	child := base.Child()
+	child.Vars["b"] = 20
+	child.Vars["c"] = 30
+	child.Funcs.Add("half", func(x float64) float64 { return x / 2 })
+
+	expr, _ := Parse("double(a + b + c) + half(4)")
+	if val, err := expr.Val(child); err != nil {
+		t.Fatal(err)
+	} else if val != 104 {
+		t.Fatal("expect 104, got", val)
+	}
+
+	if len(base.Vars) != 2 || base.Vars["b"] != 2 {
+		t.Fatal("expect the parent's Vars to be left untouched, got", base.Vars)
+	}
+	if _, ok := base.LookupFunc("half"); ok {
+		t.Fatal("expect the parent not to see functions of the child")
+	}
+
+	base.Vars["a"] = 10
+	if val, _ := child.Lookup("a"); val != 10 {
+		t.Fatal("expect the child to see later changes to the parent, got", val)
+	}
+
+	if child.AngleMode != Degrees || child.MaxSteps != 100 {
+		t.Fatal("expect AngleMode and EvalOptions to be inherited")
+	}
+	if child.Rand != nil {
+		t.Fatal("expect Rand not to be shared with the child")
+	}
+
+	grandchild := child.Child()
+	if val, _ := grandchild.Lookup("c"); val != 30 {
+		t.Fatal("expect 30, got", val)
+	}
+	if len(grandchild.Functions()) != 2 {
+		t.Fatal("expect functions of all ancestors, got", grandchild.Functions())
+	}
+}
+
+func TestChildOfDefaultEnv(t *testing.T) {
+	child := DefaultEnv.Child()
+	child.Vars["scopeTestVar"] = 1
+	if _, ok := DefaultEnv.Vars["scopeTestVar"]; ok {
+		t.Fatal("expect DefaultEnv to be left untouched")
+	}
+}
+
+func TestWithVars(t *testing.T) {
+	base := Env{Vars: Vars{"x": 1, "y": 2}}
+	row := Vars{"x": 5}
+	env := base.WithVars(row)
+
+	expr, _ := Parse("x + y")
+	if val, err := expr.Val(env); err != nil {
+		t.Fatal(err)
+	} else if val != 7 {
+		t.Fatal("expect 7, got", val)
+	}
+
+	row["x"] = 6
+	if val, _ := expr.Val(env); val != 8 {
+		t.Fatal("expect vars to be used without copying, got", val)
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	base := Env{Vars: Vars{"pi": 3.14}, Funcs: Funcs{}}
+	base.Funcs.Add("one", func() float64 { return 1 })
+	base.Freeze()
+
+	child := base.Child()
+	child.Vars["pi"] = 3
+	child.Vars["r"] = 2
+	child.Funcs.Add("one", func() float64 { return 2 })
+
+	expr, _ := Parse("pi * r * one()")
+	if val, err := expr.Val(child); err != nil {
+		t.Fatal(err)
+	} else if val != 6.28 {
+		t.Fatal("expect 6.28, got", val)
+	}
+
+	// nor by Consts of the child
+	child.Consts = Vars{"pi": 99}
+	if val, _ := child.Lookup("pi"); val != 3.14 {
+		t.Fatal("expect 3.14, got", val)
+	}
+	if opt := Optimize(expr, child); opt.String() != expr.String() {
+		t.Fatal("expect pi not to be inlined, got", opt)
+	}
+
+	// Names of a frozen grandparent can not be shadowed by the parent either.
+	grandchild := child.Child()
+	if val, _ := grandchild.Lookup("pi"); val != 3.14 {
+		t.Fatal("expect 3.14, got", val)
+	}
+	if val, _ := grandchild.Lookup("r"); val != 2 {
+		t.Fatal("expect 2, got", val)
+	}
+	for _, desc := range grandchild.Functions() {
+		if desc.Name == "one" && desc.Signature != "one()" {
+			t.Fatal("unexpected", desc)
+		}
+	}
+}
@@ -1,7 +1,7 @@
 package sec
 
 import (
-	"reflect"
+	"math/rand"
 )
 
 type (
@@ -9,14 +9,61 @@ type (
 	Funcs map[string]interface{}
 
 	Env struct {
-		Vars  Vars
-		Funcs Funcs
+		// Consts holds the constants, whose names take precedence over
+		// Vars, resolvers, namespaces and children of the Env, so their
+		// values can not be overridden. Only the names of a frozen
+		// ancestor take precedence over them. Parser.Consts inlines them
+		// into expressions.
+		Consts Vars
+		Vars   Vars
+		Funcs  Funcs
+		// Info documents the functions in Funcs, see Register.
+		Info map[string]FuncInfo
+
+		// VarResolver and FuncResolver resolve the variables and functions
+		// not found in Vars and Funcs. They let hosts look names up
+		// lazily, from a database row or a cache for example.
+		VarResolver  VarResolver
+		FuncResolver FuncResolver
+
+		// AngleMode is the unit of angles taken and returned by the
+		// trigonometric functions in MathFuncs, and the unit angle
+		// literals like 30deg are converted to.
+		AngleMode AngleMode
+
+		// Rand is the source of the random functions in RandFuncs. When it
+		// is nil they use the shared source of the math/rand package. A
+		// *rand.Rand is not safe for concurrent use, so concurrent
+		// evaluations should be given Envs with distinct sources.
+		Rand *rand.Rand
+
+		EvalOptions
+
+		parent     *Env            // see Child
+		frozen     bool            // see Freeze
+		namespaces map[string]*Env // see Namespace
+	}
+
+	// EvalOptions controls how expressions are evaluated. The limits guard
+	// against expressions from untrusted sources, zero means no limit.
+	EvalOptions struct {
+		// MaxSteps limits the number of nodes visited by an evaluation.
+		MaxSteps int
+		// MaxCallDepth limits how deeply function calls may nest.
+		MaxCallDepth int
+		// CheckOverflow makes an operation on finite values that yields an
+		// infinity fail with ErrOverflow. Division by zero is not an
+		// overflow: 1/0 still yields +Inf, like 1//0, and 0 ** -1.
+		CheckOverflow bool
+		// KeepPanics lets panics raised inside functions propagate to the
+		// caller of Val, instead of being returned as ErrFuncPanicked.
+		KeepPanics bool
 	}
 )
 
 var (
 	DefaultParser Parser
-	DefaultEnv    = Env{Vars{}, Funcs{}}
+	DefaultEnv    = Env{Consts: Vars{}, Vars: Vars{}, Funcs: Funcs{}}
 )
 
 func Parse(s string) (Expr, error) { return DefaultParser.Parse(s) }
@@ -31,33 +78,22 @@ func Eval(s string) (val float64, err error) {
 // Check returns a non-nil error when at least one illegal function in Funcs.
 func (f Funcs) Check() error {
 	for fname, fun := range f {
-		funcType := reflect.TypeOf(fun)
-		if funcType.Kind() != reflect.Func {
-			return ErrNotFunction{fname}
+		if _, err := toFunction(fname, fun); err != nil {
+			return err
 		}
+	}
 
-		numIn, numOut := funcType.NumIn(), funcType.NumOut()
-		switch {
-		case numOut == 0:
-			return ErrFuncNoReturnVal{fname}
-		case numOut > 1:
-			return ErrFuncReturnTooManyVal{fname}
-		case funcType.Out(0).Kind() != reflect.Float64:
-			return ErrReturnValNotFloat64{fname}
-		}
+	return nil
+}
 
-		if funcType.IsVariadic() {
-			if reflect.SliceOf(funcType.In(numIn-1)).Kind() != reflect.Float64 {
-				return ErrParamNotFloat64{fname, numIn}
-			}
-			numIn--
-		}
-		for i := 0; i < numIn; i++ {
-			if funcType.In(i).Kind() != reflect.Float64 {
-				return ErrParamNotFloat64{fname, i + 1}
-			}
-		}
+// Add checks fun and adds it to f as name. Go functions which are not one of
+// the typed function types are converted here once, rather than on every
+// call.
+func (f Funcs) Add(name string, fun interface{}) error {
+	fn, err := toFunction(name, fun)
+	if err != nil {
+		return err
 	}
-
+	f[name] = fn
 	return nil
 }
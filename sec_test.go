@@ -27,19 +27,29 @@ func TestFuncCheck(t *testing.T) {
 		t.Fatal("expect errFuncRetTooManyVals error")
 	}
 
-	env.Funcs["f"] = func() int { return 0 }
+	env.Funcs["f"] = func() (float64, int) { return 0, 0 }
+	if _, ok := env.Funcs.Check().(ErrFuncReturnTooManyVal); !ok {
+		t.Fatal("expect errFuncRetTooManyVals error")
+	}
+
+	env.Funcs["f"] = func() (float64, error) { return 0, nil }
+	if err := env.Funcs.Check(); err != nil {
+		t.Fatal("expect no error")
+	}
+
+	env.Funcs["f"] = func() string { return "" }
 	if _, ok := env.Funcs.Check().(ErrReturnValNotFloat64); !ok {
 		t.Fatal("expect errFuncRetNotFloat64 error")
 	}
 
-	env.Funcs["f"] = func(p1 float64, p2 ...int) float64 { return 0 }
+	env.Funcs["f"] = func(p1 float64, p2 ...string) float64 { return 0 }
 	if err, ok := env.Funcs.Check().(ErrParamNotFloat64); !ok {
 		t.Fatal("expect ErrParamNotFloat64 error")
 	} else if err.N != 2 {
 		t.Fatal("param number not correct")
 	}
 
-	env.Funcs["f"] = func(p1 float64, p2 int) float64 { return 0 }
+	env.Funcs["f"] = func(p1 float64, p2 string) float64 { return 0 }
 	if err, ok := env.Funcs.Check().(ErrParamNotFloat64); !ok {
 		t.Fatal("expect errFuncParam error")
 	} else if err.N != 2 {
@@ -0,0 +1,478 @@
+package sec
+
+import (
+	"math"
+	"sort"
+)
+
+// StatsFuncs is a set of statistical and probability functions. Except for
+// the distribution functions, they are variadic and take the sample as their
+// arguments. Functions working on two samples, like covariance, take both
+// samples one after another, so they must be called with an even number of
+// arguments. Invalid input yields NaN.
+var StatsFuncs = Funcs{
+	"mean":        variadic(1, mean),
+	"median":      variadic(1, median),
+	"mode":        variadic(1, mode),
+	"variance":    FuncN(variance),
+	"varianceP":   FuncN(varianceP),
+	"stddev":      FuncN(stddev),
+	"stddevP":     FuncN(stddevP),
+	"percentile":  variadic(2, percentile),
+	"quantile":    variadic(2, quantile),
+	"zscore":      variadic(1, zscore),
+	"covariance":  FuncN(covariance),
+	"covarianceP": FuncN(covarianceP),
+	"correlation": FuncN(correlation),
+	"normPdf":     Func3(normPdf),
+	"normCdf":     Func3(normCdf),
+	"normInv":     Func3(normInv),
+	"poissonPdf":  Func2(poissonPdf),
+	"poissonCdf":  Func2(poissonCdf),
+	"binomPdf":    Func3(binomPdf),
+	"binomCdf":    Func3(binomCdf),
+	"erf":         Func1(math.Erf),
+	"erfc":        Func1(math.Erfc),
+	"gamma":       Func1(math.Gamma),
+	"beta":        Func2(beta),
+}
+
+// statsInfo documents StatsFuncs.
+var statsInfo = map[string]FuncInfo{
+	"mean":        doc("xs", "mean returns the arithmetic mean of the sample.", "mean(1, 2, 3, 4)"),
+	"median":      doc("xs", "median returns the median of the sample.", "median(3, 1, 4, 1, 5)"),
+	"mode":        doc("xs", "mode returns the most frequent value of the sample, the least of them on ties.", "mode(1, 2, 2, 3)"),
+	"variance":    doc("xs", "variance returns the sample variance.", "variance(2, 4, 4, 4, 5, 5, 7, 9)"),
+	"varianceP":   doc("xs", "varianceP returns the population variance.", "varianceP(2, 4, 4, 4, 5, 5, 7, 9)"),
+	"stddev":      doc("xs", "stddev returns the sample standard deviation.", "stddev(2, 4, 4, 4, 5, 5, 7, 9)"),
+	"stddevP":     doc("xs", "stddevP returns the population standard deviation.", "stddevP(2, 4, 4, 4, 5, 5, 7, 9)"),
+	"percentile":  doc("p, xs", "percentile returns the p-th percentile of the sample, interpolating like PERCENTILE.INC.", "percentile(90, 1, 2, 3, 4, 5)"),
+	"quantile":    doc("q, xs", "quantile returns the q-th quantile (0 <= q <= 1) of the sample, interpolating like PERCENTILE.INC.", "quantile(0.25, 1, 2, 3, 4, 5)"),
+	"zscore":      doc("x, xs", "zscore returns how many sample standard deviations x is away from the mean of the sample.", "zscore(9, 2, 4, 4, 4, 5, 5, 7, 9)"),
+	"covariance":  doc("xs", "covariance returns the sample covariance of two samples of the same size given one after another.", "covariance(1, 2, 3, 2, 4, 7)"),
+	"covarianceP": doc("xs", "covarianceP returns the population covariance of two samples of the same size given one after another.", "covarianceP(1, 2, 3, 2, 4, 7)"),
+	"correlation": doc("xs", "correlation returns the Pearson correlation coefficient of two samples of the same size given one after another.", "correlation(1, 2, 3, 2, 4, 7)"),
+	"normPdf":     doc("x, mu, sigma", "normPdf returns the density of the normal distribution at x.", "normPdf(0, 0, 1)"),
+	"normCdf":     doc("x, mu, sigma", "normCdf returns the probability that a normal variable is at most x.", "normCdf(1.96, 0, 1)"),
+	"normInv":     doc("p, mu, sigma", "normInv returns the x for which normCdf(x, mu, sigma) is p.", "normInv(0.975, 0, 1)"),
+	"poissonPdf":  doc("k, lambda", "poissonPdf returns the probability of exactly k events of a Poisson distribution with mean lambda.", "poissonPdf(2, 3)"),
+	"poissonCdf":  doc("k, lambda", "poissonCdf returns the probability of at most k events of a Poisson distribution with mean lambda.", "poissonCdf(2, 3)"),
+	"binomPdf":    doc("k, n, p", "binomPdf returns the probability of exactly k successes in n trials of probability p.", "binomPdf(3, 10, 0.5)"),
+	"binomCdf":    doc("k, n, p", "binomCdf returns the probability of at most k successes in n trials of probability p.", "binomCdf(3, 10, 0.5)"),
+	"erf":         doc("x", "erf returns the error function of x.", "erf(1)"),
+	"erfc":        doc("x", "erfc returns the complementary error function of x.", "erfc(1)"),
+	"gamma":       doc("x", "gamma returns the Gamma function of x.", "gamma(5)"),
+	"beta":        doc("a, b", "beta returns the Beta function of a and b.", "beta(2, 3)"),
+}
+
+// LoadStats adds StatsFuncs to e. Existing names are overwritten.
+func (e *Env) LoadStats() { e.load("statistics", nil, StatsFuncs, statsInfo) }
+
+func sum(xs []float64) (s float64) {
+	for _, x := range xs {
+		s += x
+	}
+	return
+}
+
+func mean(xs []float64) float64 {
+	return sum(xs) / float64(len(xs))
+}
+
+// sorted returns a sorted copy of xs.
+func sorted(xs []float64) []float64 {
+	s := append([]float64(nil), xs...)
+	sort.Float64s(s)
+	return s
+}
+
+func median(xs []float64) float64 {
+	return quantileOf(0.5, xs)
+}
+
+// mode returns the most frequent value. Ties are broken in favour of the
+// smallest value.
+func mode(xs []float64) float64 {
+	s := sorted(xs)
+	best, bestN := s[0], 0
+	for i := 0; i < len(s); {
+		j := i + 1
+		for j < len(s) && s[j] == s[i] {
+			j++
+		}
+		if j-i > bestN {
+			best, bestN = s[i], j-i
+		}
+		i = j
+	}
+	return best
+}
+
+// sumSquares returns the sum of squared deviations from the mean.
+func sumSquares(xs []float64) (ss float64) {
+	m := sum(xs) / float64(len(xs))
+	for _, x := range xs {
+		ss += (x - m) * (x - m)
+	}
+	return
+}
+
+func variance(xs []float64) float64 {
+	if len(xs) < 2 {
+		return math.NaN()
+	}
+	return sumSquares(xs) / float64(len(xs)-1)
+}
+
+func varianceP(xs []float64) float64 {
+	if len(xs) < 1 {
+		return math.NaN()
+	}
+	return sumSquares(xs) / float64(len(xs))
+}
+
+func stddev(xs []float64) float64  { return math.Sqrt(variance(xs)) }
+func stddevP(xs []float64) float64 { return math.Sqrt(varianceP(xs)) }
+
+// quantile takes q and the sample, see quantileOf.
+func quantile(args []float64) float64 { return quantileOf(args[0], args[1:]) }
+
+// quantileOf returns the q-th quantile (0 <= q <= 1) of the sample xs,
+// linearly interpolating between the closest ranks like PERCENTILE.INC does.
+func quantileOf(q float64, xs []float64) float64 {
+	if q < 0 || q > 1 || math.IsNaN(q) {
+		return math.NaN()
+	}
+	s := sorted(xs)
+	rank := q * float64(len(s)-1)
+	i := int(rank)
+	if i == len(s)-1 {
+		return s[i]
+	}
+	return s[i] + (rank-float64(i))*(s[i+1]-s[i])
+}
+
+// percentile is quantile with p given in percent.
+func percentile(args []float64) float64 {
+	return quantileOf(args[0]/100, args[1:])
+}
+
+// zscore returns how many sample standard deviations the first argument is
+// away from the mean of the sample following it.
+func zscore(args []float64) float64 {
+	x, xs := args[0], args[1:]
+	return (x - sum(xs)/float64(len(xs))) / stddev(xs)
+}
+
+// halves splits the arguments of a two-sample function into both samples.
+func halves(xs []float64) (a, b []float64, ok bool) {
+	if len(xs) == 0 || len(xs)%2 != 0 {
+		return nil, nil, false
+	}
+	return xs[:len(xs)/2], xs[len(xs)/2:], true
+}
+
+// coDeviation returns the sum of the products of paired deviations.
+func coDeviation(a, b []float64) (s float64) {
+	ma, mb := sum(a)/float64(len(a)), sum(b)/float64(len(b))
+	for i := range a {
+		s += (a[i] - ma) * (b[i] - mb)
+	}
+	return
+}
+
+func covariance(xs []float64) float64 {
+	a, b, ok := halves(xs)
+	if !ok || len(a) < 2 {
+		return math.NaN()
+	}
+	return coDeviation(a, b) / float64(len(a)-1)
+}
+
+func covarianceP(xs []float64) float64 {
+	a, b, ok := halves(xs)
+	if !ok {
+		return math.NaN()
+	}
+	return coDeviation(a, b) / float64(len(a))
+}
+
+// correlation returns the Pearson correlation coefficient.
+func correlation(xs []float64) float64 {
+	a, b, ok := halves(xs)
+	if !ok {
+		return math.NaN()
+	}
+	return coDeviation(a, b) / math.Sqrt(sumSquares(a)*sumSquares(b))
+}
+
+func normPdf(x, mu, sigma float64) float64 {
+	if sigma <= 0 {
+		return math.NaN()
+	}
+	z := (x - mu) / sigma
+	return math.Exp(-z*z/2) / (sigma * math.Sqrt(2*math.Pi))
+}
+
+func normCdf(x, mu, sigma float64) float64 {
+	if sigma <= 0 {
+		return math.NaN()
+	}
+	return math.Erfc(-(x-mu)/(sigma*math.Sqrt2)) / 2
+}
+
+// normInv is the inverse of normCdf.
+func normInv(p, mu, sigma float64) float64 {
+	if sigma <= 0 || p < 0 || p > 1 {
+		return math.NaN()
+	}
+	return mu + sigma*math.Sqrt2*math.Erfinv(2*p-1)
+}
+
+func isNatural(x float64) bool {
+	return x >= 0 && isInteger(x)
+}
+
+func lchoose(n, k float64) float64 {
+	a, _ := math.Lgamma(n + 1)
+	b, _ := math.Lgamma(k + 1)
+	c, _ := math.Lgamma(n - k + 1)
+	return a - b - c
+}
+
+// poissonPdf returns the probability of exactly k events.
+func poissonPdf(k, lambda float64) float64 {
+	if !isNatural(k) || lambda < 0 {
+		return math.NaN()
+	}
+	if lambda == 0 {
+		if k == 0 {
+			return 1
+		}
+		return 0
+	}
+	lg, _ := math.Lgamma(k + 1)
+	return math.Exp(k*math.Log(lambda) - lambda - lg)
+}
+
+// poissonCdf returns the probability of at most k events.
+func poissonCdf(k, lambda float64) float64 {
+	if !isNatural(k) || lambda < 0 {
+		return math.NaN()
+	}
+	if lambda == 0 {
+		return 1
+	}
+	_, q := regGamma(k+1, lambda)
+	return q
+}
+
+// binomPdf returns the probability of exactly k successes in n trials.
+func binomPdf(k, n, p float64) float64 {
+	if !isNatural(k) || !isNatural(n) || p < 0 || p > 1 {
+		return math.NaN()
+	}
+	if k > n {
+		return 0
+	}
+	switch p {
+	case 0:
+		if k == 0 {
+			return 1
+		}
+		return 0
+	case 1:
+		if k == n {
+			return 1
+		}
+		return 0
+	}
+	return math.Exp(lchoose(n, k) + k*math.Log(p) + (n-k)*math.Log1p(-p))
+}
+
+// binomCdf returns the probability of at most k successes in n trials.
+func binomCdf(k, n, p float64) float64 {
+	if !isNatural(k) || !isNatural(n) || p < 0 || p > 1 {
+		return math.NaN()
+	}
+	if k >= n || p == 0 {
+		return 1
+	}
+	if p == 1 {
+		return 0
+	}
+	return regBeta(1-p, n-k, k+1)
+}
+
+func beta(a, b float64) float64 {
+	la, sa := math.Lgamma(a)
+	lb, sb := math.Lgamma(b)
+	lab, sab := math.Lgamma(a + b)
+	return float64(sa*sb*sab) * math.Exp(la+lb-lab)
+}
+
+// Beyond largeParam the iterations of regGamma and regBeta, about the
+// square root of their parameters, get too many, and the normal
+// approximations they use instead are accurate. maxIter is a last resort
+// bound on the iterations.
+const (
+	largeParam = 1e12
+	maxIter    = 10000000
+)
+
+// regGamma returns the regularized lower and upper incomplete gamma
+// functions P(a, x) and Q(a, x), as in Numerical Recipes.
+func regGamma(a, x float64) (p, q float64) {
+	if x <= 0 {
+		return 0, 1
+	}
+	if a < largeParam {
+		// x^a e^-x / Gamma(a)
+		front := a * poissonRaw(a, x)
+		if x < a+1 {
+			// series
+			sum, del := 1/a, 1/a
+			for n := 1.0; n < maxIter; n++ {
+				del *= x / (a + n)
+				if sum += del; math.Abs(del) < math.Abs(sum)*1e-16 {
+					p = sum * front
+					return p, 1 - p
+				}
+			}
+		} else {
+			// continued fraction by the modified Lentz's method
+			b := x + 1 - a
+			c, d := 1/tiny, 1/b
+			h := d
+			for i := 1.0; i < maxIter; i++ {
+				an := -i * (i - a)
+				b += 2
+				d = 1 / nonZero(an*d+b)
+				c = nonZero(b + an/c)
+				del := d * c
+				if h *= del; math.Abs(del-1) < 1e-15 {
+					q = front * h
+					return 1 - q, q
+				}
+			}
+		}
+	}
+
+	// Wilson-Hilferty approximation
+	z := (math.Cbrt(x/a) - 1 + 1/(9*a)) * math.Sqrt(9*a)
+	p = normCdf(z, 0, 1)
+	return p, 1 - p
+}
+
+// regBeta returns the regularized incomplete beta function I_x(a, b) for
+// a, b >= 1, as in Numerical Recipes.
+func regBeta(x, a, b float64) float64 {
+	if x <= 0 {
+		return 0
+	} else if x >= 1 {
+		return 1
+	}
+	if x > (a+1)/(a+b+2) {
+		return 1 - regBeta(1-x, b, a)
+	}
+
+	n := a + b - 1
+	if n < largeParam {
+		// x^a (1-x)^b / (a B(a, b))
+		front := (1 - x) * binomRaw(a, n, x, 1-x)
+
+		// continued fraction by the modified Lentz's method
+		c, d := 1.0, 1/nonZero(1-(a+b)*x/(a+1))
+		h := d
+		for m := 1.0; m < maxIter; m++ {
+			m2 := 2 * m
+			an := m * (b - m) * x / ((a + m2 - 1) * (a + m2))
+			d = 1 / nonZero(1+an*d)
+			c = nonZero(1 + an/c)
+			h *= d * c
+			an = -(a + m) * (a + b + m) * x / ((a + m2) * (a + m2 + 1))
+			d = 1 / nonZero(1+an*d)
+			c = nonZero(1 + an/c)
+			del := d * c
+			if h *= del; math.Abs(del-1) < 1e-15 {
+				return front * h
+			}
+		}
+	}
+
+	// normal approximation of the binomial distribution, I_x(a, b) is the
+	// probability of at least a successes in n trials of probability x
+	return 1 - normCdf(a-0.5, n*x, math.Sqrt(n*x*(1-x)))
+}
+
+// tiny keeps the denominators of the Lentz's method from being zero.
+const tiny = 1e-300
+
+func nonZero(x float64) float64 {
+	if math.Abs(x) < tiny {
+		return tiny
+	}
+	return x
+}
+
+// The functions below compute the Poisson and binomial probabilities
+// without the cancellation of log-gammas of large arguments, after C.
+// Loader, "Fast and Accurate Computation of Binomial Probabilities", 2000.
+
+// stirlerr returns log(n!) - log(sqrt(2*pi*n)*(n/e)^n).
+func stirlerr(n float64) float64 {
+	const (
+		s0 = 1.0 / 12
+		s1 = 1.0 / 360
+		s2 = 1.0 / 1260
+		s3 = 1.0 / 1680
+		s4 = 1.0 / 1188
+	)
+	if n == 0 {
+		return 0
+	}
+	if n <= 15 {
+		lg, _ := math.Lgamma(n + 1)
+		return lg - (n+0.5)*math.Log(n) + n - math.Log(math.Sqrt(2*math.Pi))
+	}
+	nn := n * n
+	return (s0 - (s1-(s2-(s3-s4/nn)/nn)/nn)/nn) / n
+}
+
+// bd0 returns x*log(x/np) + np - x.
+func bd0(x, np float64) float64 {
+	if math.Abs(x-np) >= 0.1*(x+np) {
+		return x*math.Log(x/np) + np - x
+	}
+	v := (x - np) / (x + np)
+	s, ej := (x-np)*v, 2*x*v
+	for j := 3.0; ; j += 2 {
+		ej *= v * v
+		s1 := s + ej/j
+		if s1 == s {
+			return s
+		}
+		s = s1
+	}
+}
+
+// poissonRaw returns the probability of x events, x may be fractional.
+func poissonRaw(x, lambda float64) float64 {
+	if x == 0 {
+		return math.Exp(-lambda)
+	}
+	return math.Exp(-stirlerr(x)-bd0(x, lambda)) / math.Sqrt(2*math.Pi*x)
+}
+
+// binomRaw returns the probability of x successes in n trials, x and n may
+// be fractional.
+func binomRaw(x, n, p, q float64) float64 {
+	switch x {
+	case 0:
+		return math.Exp(n * math.Log(q))
+	case n:
+		return math.Exp(n * math.Log(p))
+	}
+	lc := stirlerr(n) - stirlerr(x) - stirlerr(n-x) - bd0(x, n*p) - bd0(n-x, n*q)
+	return math.Exp(lc) * math.Sqrt(n/(2*math.Pi*x*(n-x)))
+}
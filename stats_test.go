@@ -0,0 +1,97 @@
+package sec
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStatsFuncsCheck(t *testing.T) {
+	if err := StatsFuncs.Check(); err != nil {
+		t.Fatal("expect no error, got", err)
+	}
+}
+
+func TestLoadStats(t *testing.T) {
+	var env Env
+	env.LoadStats()
+
+	cases := map[string]float64{
+		"mean(1, 2, 3, 4)":                  2.5,
+		"median(5, 1, 3)":                   3,
+		"median(4, 1, 3, 2)":                2.5,
+		"mode(1, 2, 2, 3, 3)":               2,
+		"variance(2, 4, 4, 4, 5, 5, 7, 9)":  32.0 / 7,
+		"varianceP(2, 4, 4, 4, 5, 5, 7, 9)": 4,
+		"stddevP(2, 4, 4, 4, 5, 5, 7, 9)":   2,
+		"percentile(50, 1, 2, 3, 4, 5)":     3,
+		"quantile(0.25, 1, 2, 3, 4)":        1.75,
+		"zscore(7, 2, 4, 4, 4, 5, 5, 7, 9)": 2 / math.Sqrt(32.0/7),
+		"covarianceP(1, 2, 3, 2, 4, 6)":     4.0 / 3,
+		"covariance(1, 2, 3, 2, 4, 6)":      2,
+		"correlation(1, 2, 3, 6, 4, 2)":     -1,
+		"normCdf(0, 0, 1)":                  0.5,
+		"normInv(normCdf(1.5, 2, 3), 2, 3)": 1.5,
+		"normPdf(0, 0, 1)":                  1 / math.Sqrt(2*math.Pi),
+		"poissonPdf(0, 2)":                  math.Exp(-2),
+		"poissonCdf(1, 2)":                  3 * math.Exp(-2),
+		"binomPdf(2, 4, 0.5)":               0.375,
+		"binomCdf(4, 4, 0.3)":               1,
+		"erf(0) + erfc(0)":                  1,
+		"gamma(5)":                          24,
+		"beta(2, 3)":                        1.0 / 12,
+	}
+
+	expectVals(t, env, 1e-9, cases)
+}
+
+func TestStatsInvalidInput(t *testing.T) {
+	for i, v := range []float64{
+		variance([]float64{1}),
+		covariance([]float64{1, 2, 3}),
+		quantile([]float64{1.5, 1, 2}),
+		normPdf(0, 0, -1),
+		binomPdf(1.5, 3, 0.5),
+	} {
+		if !math.IsNaN(v) {
+			t.Fatal(i, "expect NaN, got", v)
+		}
+	}
+}
+
+func TestDiscreteCdf(t *testing.T) {
+	// compare with summing the probabilities
+	for _, lambda := range []float64{0.1, 1, 4.5, 30, 200} {
+		var sum float64
+		for k := 0.0; k <= 400; k++ {
+			sum += poissonPdf(k, lambda)
+			if got := poissonCdf(k, lambda); math.Abs(got-sum) > 1e-12 {
+				t.Fatalf("poissonCdf(%v, %v): expect %v, got %v", k, lambda, sum, got)
+			}
+		}
+	}
+	for _, n := range []float64{1, 7, 50, 300} {
+		for _, p := range []float64{0.01, 0.3, 0.5, 0.95} {
+			var sum float64
+			for k := 0.0; k <= n; k++ {
+				sum += binomPdf(k, n, p)
+				if got := binomCdf(k, n, p); math.Abs(got-sum) > 1e-12 {
+					t.Fatalf("binomCdf(%v, %v, %v): expect %v, got %v", k, n, p, sum, got)
+				}
+			}
+		}
+	}
+
+	// huge arguments must not hang
+	for _, c := range []struct{ got, want, tolerance float64 }{
+		{poissonCdf(1e16, 1), 1, 0},
+		{poissonCdf(1e16, 1e16), 0.5, 1e-6},
+		{poissonCdf(1e6, 1e6), 0.50026596, 1e-8},
+		{binomCdf(1e9, 1e9, 0.5), 1, 0},
+		{binomCdf(5e8, 1e9, 0.5), 0.50001261566, 1e-10},
+		{binomCdf(1e15, 2e15, 0.5), 0.5, 1e-6},
+	} {
+		if math.Abs(c.got-c.want) > c.tolerance {
+			t.Fatalf("expect %v, got %v", c.want, c.got)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package sec
+
+import (
+	"reflect"
+)
+
+// structResolver resolves the fields of a struct as variables. The fields
+// are read when they are looked up, so a resolver made of a pointer sees
+// later changes to the struct.
+type structResolver struct {
+	v      reflect.Value
+	fields map[string][]int // index paths of fields by name
+}
+
+// EnvFromStruct makes an Env of v, a struct or a pointer to a struct.
+//
+// Exported fields of numeric or bool types become variables, named after
+// the fields. The fields of nested structs are named after the path to
+// them, like Customer.Discount, while the fields of embedded structs are
+// promoted. Structs nested in themselves are not followed. A field tagged
+// `sec:"name"` is named name instead, and a field tagged `sec:"-"` is
+// hidden. Exported methods accepted by Funcs become functions named after
+// the methods.
+//
+// The variables are resolved by the VarResolver of the Env. Its Vars and
+// Funcs may be added to as usual.
+func EnvFromStruct(v interface{}) (Env, error) {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return Env{}, ErrNotStruct{"nil"}
+	}
+	st := rv.Type()
+	if st.Kind() == reflect.Ptr {
+		st = st.Elem()
+	}
+	if st.Kind() != reflect.Struct || rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return Env{}, ErrNotStruct{rv.Type().String()}
+	}
+
+	r := structResolver{v: rv, fields: make(map[string][]int)}
+	r.addFields(st, "", nil, map[reflect.Type]bool{st: true})
+
+	env := Env{Vars: Vars{}, Funcs: Funcs{}, VarResolver: r}
+	for i := 0; i < rv.NumMethod(); i++ {
+		name := rv.Type().Method(i).Name
+		if fn, err := toFunction(name, rv.Method(i).Interface()); err == nil {
+			env.Funcs[name] = fn
+		}
+	}
+	return env, nil
+}
+
+// addFields adds the fields of the struct type t, reached by path, whose
+// names are prefixed with prefix. seen holds the types on the path, so that
+// recursive types end.
+func (r structResolver) addFields(t reflect.Type, prefix string, path []int, seen map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue // unexported
+		}
+		name := f.Name
+		if tag := f.Tag.Get("sec"); tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+
+		index := make([]int, len(path)+1)
+		copy(index, path)
+		index[len(path)] = i
+
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		switch {
+		case ft.Kind() == reflect.Struct:
+			if seen[ft] {
+				continue
+			}
+			seen[ft] = true
+			if f.Anonymous && f.Tag.Get("sec") == "" {
+				r.addFields(ft, prefix, index, seen)
+			} else if f.PkgPath == "" {
+				r.addFields(ft, prefix+name+".", index, seen)
+			}
+			delete(seen, ft)
+		case f.PkgPath == "" && ft == f.Type && isNumeric(ft):
+			// like in Go, shallower fields win over promoted ones
+			if old, ok := r.fields[prefix+name]; !ok || len(index) < len(old) {
+				r.fields[prefix+name] = index
+			}
+		}
+	}
+}
+
+func (r structResolver) Lookup(name string) (val float64, ok bool) {
+	index, ok := r.fields[name]
+	if !ok {
+		return 0, false
+	}
+	v := r.v
+	for _, i := range index {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return 0, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return fromValue(v), true
+}
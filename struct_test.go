@@ -0,0 +1,105 @@
+package sec
+
+import (
+	"errors"
+	"testing"
+)
+
+type (
+	testCustomer struct {
+		Discount float64
+		Level    int8 `sec:"level"`
+	}
+
+	testAudit struct {
+		Version uint
+		Qty     int // shadowed by order.Qty
+	}
+
+	testOrder struct {
+		testAudit
+		Price    float64
+		Qty      int
+		Paid     bool
+		Note     string
+		Secret   float64 `sec:"-"`
+		Customer testCustomer
+		Shipping *testCustomer `sec:"ship"`
+		Next     *testOrder
+		internal float64
+	}
+)
+
+func (o testOrder) Total(tax float64) float64 {
+	return o.Price * float64(o.Qty) * (1 + tax) * (1 - o.Customer.Discount)
+}
+
+func (o testOrder) Label() string { return o.Note }
+
+func TestEnvFromStruct(t *testing.T) {
+	order := &testOrder{
+		testAudit: testAudit{Version: 3, Qty: 100},
+		Price:     10,
+		Qty:       2,
+		Paid:      true,
+		Secret:    42,
+		Customer:  testCustomer{Discount: 0.5, Level: 2},
+		internal:  1,
+	}
+	env, err := EnvFromStruct(order)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for src, expected := range map[string]float64{
+		"Price * Qty":                  20,
+		"Paid + Version":               4,
+		"Customer.Discount":            0.5,
+		"Customer.level":               2,
+		"Total(0.25)":                  12.5,
+		"Total(Customer.level // 2.0)": 20,
+	} {
+		expr, err := Parse(src)
+		if err != nil {
+			t.Fatal(src, err)
+		}
+		if val, err := expr.Val(env); err != nil {
+			t.Fatal(src, err)
+		} else if val != expected {
+			t.Fatalf("%s: expect %v, got %v", src, expected, val)
+		}
+	}
+
+	for _, name := range []string{"Note", "Secret", "internal", "ship.Discount", "Next.Price", "Customer"} {
+		expr, _ := Parse(name)
+		if _, err := expr.Val(env); err == nil {
+			t.Fatalf("expect %s to be undeclared", name)
+		}
+	}
+	if _, ok := env.LookupFunc("Label"); ok {
+		t.Fatal("expect methods not taking numbers to be skipped")
+	}
+
+	// fields are read lazily
+	order.Qty = 3
+	order.Shipping = &testCustomer{Discount: 0.1}
+	order.Next = &testOrder{Price: 7}
+	for name, expected := range map[string]float64{"Qty": 3, "ship.Discount": 0.1} {
+		if val, _ := env.Lookup(name); val != expected {
+			t.Fatalf("%s: expect %v, got %v", name, expected, val)
+		}
+	}
+	if _, ok := env.Lookup("Next.Price"); ok {
+		t.Fatal("expect recursive types not to be followed")
+	}
+}
+
+func TestEnvFromNonStruct(t *testing.T) {
+	var order *testOrder
+	for _, v := range []interface{}{nil, 1, order, &order} {
+		var serr ErrNotStruct
+		if _, err := EnvFromStruct(v); !errors.As(err, &serr) {
+			t.Fatalf("%T: expect ErrNotStruct error, got %v", v, err)
+		}
+	}
+}
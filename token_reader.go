@@ -34,6 +34,7 @@ type (
 const (
 	initial tokenType = iota
 	identifier
+	identifierDot // identifier ending with '.'
 	zero
 	integer
 	float
@@ -81,6 +82,8 @@ func (t tokenType) String() (str string) {
 		str = "initial"
 	case identifier:
 		str = "identifier"
+	case identifierDot:
+		str = "identifier-dot"
 	case zero:
 		str = "zero"
 	case integer:
@@ -145,7 +148,7 @@ func (t *tokenReader) read() (tk token, err error) {
 		switch tk.typ {
 		case initial:
 			if isBlank(ch) {
-				// skip blank
+				tk.afterBlank = true
 			} else if ch == '\r' || ch == '\n' {
 				if ch == '\r' {
 					if ch, _, _ = t.src.ReadRune(); ch != '\n' {
@@ -153,6 +156,7 @@ func (t *tokenReader) read() (tk token, err error) {
 						return
 					}
 				}
+				tk.afterNewLine = true
 				t.Row++
 				prevLineCols = t.Col
 				t.Col = 1
@@ -214,10 +218,22 @@ func (t *tokenReader) read() (tk token, err error) {
 		case identifier:
 			if isAlpha(ch) || isNumber(ch) || ch == '_' {
 				t.text.WriteRune(ch)
+			} else if ch == '.' {
+				t.text.WriteRune(ch)
+				tk.typ = identifierDot
 			} else {
 				unread = true
 				finish = true
 			}
+		case identifierDot:
+			// a dot in an identifier must be followed by a letter or '_'
+			if isAlpha(ch) || ch == '_' {
+				t.text.WriteRune(ch)
+				tk.typ = identifier
+			} else {
+				err = secError{ErrUnexpected{Position{t.Row, t.Col - 2}, '.'}}
+				return
+			}
 		case zero:
 			if isNumber(ch) {
 				t.text.WriteRune(ch)
@@ -324,6 +340,8 @@ func (t *tokenReader) read() (tk token, err error) {
 		tk.typ, err = EOF, io.EOF
 	case zero:
 		tk.typ = integer
+	case identifierDot:
+		err = secError{ErrUnexpected{Position{t.Row, t.Col - 1}, '.'}}
 	case binLiteralPrefix, octLiteralPrefix, hexLiteralPrefix:
 		err = explainLiteralPrefixError(tk, t)
 	}
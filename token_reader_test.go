@@ -143,3 +143,23 @@ func TestReadToken(t *testing.T) {
 		}
 	}
 }
+
+func TestReadDottedIdentifier(t *testing.T) {
+	var r tokenReader
+	r.load("order.customer._rate2 ")
+	if tk, err := r.read(); err != nil {
+		t.Fatal(err)
+	} else if tk.typ != identifier || tk.txt != "order.customer._rate2" {
+		t.Fatal("unexpected token", tk.typ, tk.txt)
+	}
+
+	for txt, col := range map[string]int{"order.": 6, "order. x": 6, "order.1": 6, "a..b": 2} {
+		r.load(txt)
+		var uerr ErrUnexpected
+		if _, err := r.read(); !errors.As(err, &uerr) {
+			t.Fatalf("%q: expect ErrUnexpected error, got %v", txt, err)
+		} else if uerr.Char != '.' || uerr.Col != col {
+			t.Fatalf("%q: unexpected %v at %v", txt, uerr.Char, uerr.Position)
+		}
+	}
+}